@@ -0,0 +1,343 @@
+package scientist
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/freshworks/go-scientist/internal/stats"
+)
+
+// BenchmarkOptions configures Benchmark.
+type BenchmarkOptions struct {
+	// Iterations of each behavior that are measured. Defaults to 1000.
+	Iterations int
+	// Warmup iterations of each behavior that are discarded. Nil defaults to
+	// a tenth of Iterations.
+	Warmup *int
+	// Seed for the order the behaviors run in each round. Zero picks one,
+	// which is recorded in the report.
+	Seed int64
+	// Outliers is the Tukey fence factor: runtimes more than Outliers times
+	// the interquartile range away from the quartiles are discarded.
+	// Defaults to 1.5; a negative value keeps every runtime.
+	Outliers float64
+	// Alpha is the significance level of the comparisons. Defaults to 0.05.
+	Alpha float64
+}
+
+// BenchmarkReport summarizes a Benchmark.
+type BenchmarkReport struct {
+	Experiment  string
+	Seed        int64
+	Control     BehaviorBenchmark
+	Candidates  []BehaviorBenchmark
+	Comparisons []BenchmarkComparison
+}
+
+// BehaviorBenchmark summarizes the runtimes of a behavior, after discarding
+// outliers. Allocations are measured in a separate pass, off the timed runs,
+// and read from the runtime's process wide counters, so they include
+// allocations made by other goroutines.
+type BehaviorBenchmark struct {
+	Name        string
+	Runs        int
+	Outliers    int
+	Errors      int
+	Min         time.Duration
+	Mean        time.Duration
+	Median      time.Duration
+	P95         time.Duration
+	P99         time.Duration
+	Max         time.Duration
+	AllocsPerOp float64
+	BytesPerOp  float64
+	runtimes    []time.Duration
+}
+
+// BenchmarkComparison compares a candidate's runtimes to the control's with
+// a Mann-Whitney U test.
+type BenchmarkComparison struct {
+	Candidate string
+	// Speedup is the control's median over the candidate's: above 1 means
+	// the candidate is faster.
+	Speedup float64
+	U       float64
+	Z       float64
+	P       float64
+	// Faster and Slower are set when the difference is significant.
+	Faster bool
+	Slower bool
+}
+
+// Benchmark runs the control and candidates of an experiment many times,
+// interleaved in a random order every round, and compares their runtimes.
+// Hooks like RunIf and Publish are not called.
+func Benchmark[T any](ctx context.Context, e *Experiment[T], opts BenchmarkOptions) (*BenchmarkReport, error) {
+	if e.control == nil {
+		return nil, behaviorNotFound(e, controlBehavior)
+	}
+
+	if opts.Iterations <= 0 {
+		opts.Iterations = 1000
+	}
+	warmup := opts.Iterations / 10
+	if opts.Warmup != nil {
+		warmup = max(*opts.Warmup, 0)
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	if opts.Outliers == 0 {
+		opts.Outliers = 1.5
+	}
+	if opts.Alpha <= 0 {
+		opts.Alpha = 0.05
+	}
+
+	control := e.control
+	fns := []func(context.Context) error{func(ctx context.Context) error {
+		_, err := control.fn(ctx)
+		return err
+	}}
+	names := []string{control.name}
+	for _, b := range e.behaviors {
+		if b.disabled.Load() {
+			continue
+		}
+		b := b
+		fns = append(fns, func(ctx context.Context) error {
+			_, err := b.fn(ctx)
+			return err
+		})
+		names = append(names, b.name)
+	}
+
+	samples := make([]benchmarkSamples, len(fns))
+	order := make([]int, len(fns))
+	for i := range order {
+		order[i] = i
+	}
+
+	r := rand.New(rand.NewSource(opts.Seed))
+	for round := 0; round < warmup+opts.Iterations; round++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		r.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+
+		for _, i := range order {
+			elapsed, err := measure(ctx, fns[i])
+			if round < warmup {
+				continue
+			}
+			samples[i].add(elapsed, err)
+		}
+	}
+
+	// Reading the memory statistics stops the world, so it's kept out of the
+	// timed runs.
+	for i, fn := range fns {
+		samples[i].allocs, samples[i].bytes = allocsPerRun(ctx, fn, min(opts.Iterations, benchmarkAllocRuns))
+	}
+
+	report := &BenchmarkReport{Experiment: e.Name, Seed: opts.Seed}
+	for i, s := range samples {
+		bb := s.summarize(names[i], opts.Outliers)
+		if i == 0 {
+			report.Control = bb
+			continue
+		}
+		report.Candidates = append(report.Candidates, bb)
+		report.Comparisons = append(report.Comparisons, compare(report.Control, bb, opts.Alpha))
+	}
+	return report, nil
+}
+
+// benchmarkAllocRuns is the most runs of a behavior that allocations are
+// averaged over.
+const benchmarkAllocRuns = 100
+
+type benchmarkSamples struct {
+	runtimes []time.Duration
+	errors   int
+	// allocs and bytes are per run.
+	allocs float64
+	bytes  float64
+}
+
+func (s *benchmarkSamples) add(runtime time.Duration, err error) {
+	s.runtimes = append(s.runtimes, runtime)
+	if err != nil {
+		s.errors++
+	}
+}
+
+func (s *benchmarkSamples) summarize(name string, outliers float64) BehaviorBenchmark {
+	sorted := append([]time.Duration(nil), s.runtimes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	kept := sorted
+	if outliers > 0 && len(sorted) >= 4 {
+		q1, q3 := stats.Percentile(sorted, 25), stats.Percentile(sorted, 75)
+		fence := time.Duration(outliers * float64(q3-q1))
+		kept = make([]time.Duration, 0, len(sorted))
+		for _, d := range sorted {
+			if d >= q1-fence && d <= q3+fence {
+				kept = append(kept, d)
+			}
+		}
+	}
+
+	bb := BehaviorBenchmark{
+		Name:     name,
+		Runs:     len(kept),
+		Outliers: len(sorted) - len(kept),
+		Errors:   s.errors,
+		runtimes: kept,
+	}
+	if len(kept) == 0 {
+		return bb
+	}
+
+	var total time.Duration
+	for _, d := range kept {
+		total += d
+	}
+	bb.Min = kept[0]
+	bb.Max = kept[len(kept)-1]
+	bb.Mean = total / time.Duration(len(kept))
+	bb.Median = stats.Percentile(kept, 50)
+	bb.P95 = stats.Percentile(kept, 95)
+	bb.P99 = stats.Percentile(kept, 99)
+	bb.AllocsPerOp = s.allocs
+	bb.BytesPerOp = s.bytes
+	return bb
+}
+
+// measure runs fn once, recovering from panics.
+func measure(ctx context.Context, fn func(context.Context) error) (elapsed time.Duration, err error) {
+	start := time.Now()
+
+	defer func() {
+		elapsed = time.Since(start)
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recover from bad behavior: %v", r)
+		}
+	}()
+
+	return 0, fn(ctx)
+}
+
+// allocsPerRun returns the average number of allocations and bytes allocated
+// by runs of fn, reading the memory statistics once around all of them like
+// testing.AllocsPerRun.
+func allocsPerRun(ctx context.Context, fn func(context.Context) error, runs int) (allocs, bytes float64) {
+	if runs <= 0 {
+		return 0, 0
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < runs; i++ {
+		measure(ctx, fn)
+	}
+	runtime.ReadMemStats(&after)
+	return float64(after.Mallocs-before.Mallocs) / float64(runs), float64(after.TotalAlloc-before.TotalAlloc) / float64(runs)
+}
+
+// compare runs a two-sided Mann-Whitney U test with a normal approximation
+// and tie correction.
+func compare(control, candidate BehaviorBenchmark, alpha float64) BenchmarkComparison {
+	c := BenchmarkComparison{Candidate: candidate.Name, P: 1}
+	if candidate.Median > 0 {
+		c.Speedup = float64(control.Median) / float64(candidate.Median)
+	}
+
+	n1, n2 := len(candidate.runtimes), len(control.runtimes)
+	if n1 == 0 || n2 == 0 {
+		return c
+	}
+
+	type sample struct {
+		d         time.Duration
+		candidate bool
+	}
+	all := make([]sample, 0, n1+n2)
+	for _, d := range candidate.runtimes {
+		all = append(all, sample{d, true})
+	}
+	for _, d := range control.runtimes {
+		all = append(all, sample{d, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].d < all[j].d })
+
+	var rankSum, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].d == all[i].d {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // average of ranks i+1..j
+		for k := i; k < j; k++ {
+			if all[k].candidate {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	fn1, fn2 := float64(n1), float64(n2)
+	n := fn1 + fn2
+	c.U = rankSum - fn1*(fn1+1)/2
+	mean := fn1 * fn2 / 2
+	sigma := math.Sqrt(fn1 * fn2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return c
+	}
+
+	c.Z = (c.U - mean) / sigma
+	c.P = math.Erfc(math.Abs(c.Z) / math.Sqrt2)
+	if c.P < alpha {
+		// a low U means the candidate's runtimes rank lower, i.e. faster
+		c.Faster = c.Z < 0
+		c.Slower = c.Z > 0
+	}
+	return c
+}
+
+func (r *BenchmarkReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "experiment %s (seed %d)\n", r.Experiment, r.Seed)
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "behavior\truns\toutliers\terrors\tmean\tmedian\tp95\tp99\tallocs/op\tB/op\t")
+	for _, bb := range append([]BehaviorBenchmark{r.Control}, r.Candidates...) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t%.1f\t%.0f\t\n",
+			bb.Name, bb.Runs, bb.Outliers, bb.Errors, bb.Mean, bb.Median, bb.P95, bb.P99, bb.AllocsPerOp, bb.BytesPerOp)
+	}
+	w.Flush()
+
+	for _, c := range r.Comparisons {
+		verdict := "no significant difference"
+		switch {
+		case c.Faster:
+			verdict = "faster"
+		case c.Slower:
+			verdict = "slower"
+		}
+		fmt.Fprintf(&b, "%s: %s (%.2fx, p=%.4f)\n", c.Candidate, verdict, c.Speedup, c.P)
+	}
+	return b.String()
+}
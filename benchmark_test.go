@@ -0,0 +1,119 @@
+package scientist
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchmark(t *testing.T) {
+	e := New[int]("benchmark")
+	e.Use(func(ctx context.Context) (int, error) {
+		time.Sleep(2 * time.Millisecond)
+		return 1, nil
+	})
+	e.Behavior("fast", func(ctx context.Context) (any, error) {
+		return 1, nil
+	})
+	e.Behavior("allocating", func(ctx context.Context) (any, error) {
+		time.Sleep(2 * time.Millisecond)
+		return make([]byte, 1<<20), nil
+	})
+
+	report, err := Benchmark(context.Background(), e, BenchmarkOptions{Iterations: 30, Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Seed != 42 || report.Control.Name != "control" || len(report.Candidates) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if runs := report.Control.Runs + report.Control.Outliers; runs != 30 {
+		t.Errorf("expected 30 measured control runs, got %d", runs)
+	}
+	if report.Control.Median < 2*time.Millisecond {
+		t.Errorf("unexpected control median: %s", report.Control.Median)
+	}
+
+	fast := report.Comparisons[0]
+	if fast.Candidate != "fast" || !fast.Faster || fast.Slower || fast.P >= 0.05 || fast.Speedup <= 1 {
+		t.Errorf("expected fast to be significantly faster: %+v", fast)
+	}
+
+	if allocating := report.Candidates[1]; allocating.BytesPerOp < 1<<20 {
+		t.Errorf("expected allocations to be measured: %+v", allocating)
+	}
+
+	if s := report.String(); !strings.Contains(s, "fast: faster") {
+		t.Errorf("unexpected report:\n%s", s)
+	}
+}
+
+func TestBenchmarkComparison(t *testing.T) {
+	same := BehaviorBenchmark{Name: "same", Median: time.Millisecond, runtimes: []time.Duration{1, 2, 3, 4, 5, 6, 7, 8}}
+	c := compare(same, same, 0.05)
+	if c.Faster || c.Slower || c.P < 0.9 {
+		t.Errorf("expected identical samples not to differ: %+v", c)
+	}
+
+	slow := BehaviorBenchmark{Name: "slow", Median: 2 * time.Millisecond, runtimes: []time.Duration{11, 12, 13, 14, 15, 16, 17, 18}}
+	c = compare(same, slow, 0.05)
+	if !c.Slower || c.Faster || c.U != 64 {
+		t.Errorf("expected slow to be significantly slower: %+v", c)
+	}
+}
+
+func TestBenchmarkOutliers(t *testing.T) {
+	s := benchmarkSamples{}
+	for i := 0; i < 20; i++ {
+		s.add(time.Millisecond, nil)
+	}
+	s.add(time.Second, nil)
+
+	if bb := s.summarize("control", 1.5); bb.Outliers != 1 || bb.Max != time.Millisecond {
+		t.Errorf("expected the outlier to be discarded: %+v", bb)
+	}
+	if bb := s.summarize("control", -1); bb.Outliers != 0 || bb.Max != time.Second {
+		t.Errorf("expected the outlier to be kept: %+v", bb)
+	}
+}
+
+func TestBenchmarkWarmup(t *testing.T) {
+	e := New[int]("benchmark")
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	runs := 0
+	e.Try(func(ctx context.Context) (any, error) {
+		runs++
+		if runs == 1 {
+			panic("cold")
+		}
+		return 1, nil
+	})
+
+	report, err := Benchmark(context.Background(), e, BenchmarkOptions{Iterations: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Candidates[0].Errors != 0 {
+		t.Errorf("expected the first run to be a discarded warm-up: %+v", report.Candidates[0])
+	}
+
+	runs = 0
+	none := 0
+	report, err = Benchmark(context.Background(), e, BenchmarkOptions{Iterations: 20, Warmup: &none})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Candidates[0].Errors != 1 {
+		t.Errorf("expected the first run to be measured without a warm-up: %+v", report.Candidates[0])
+	}
+}
+
+func TestBenchmarkWithoutControl(t *testing.T) {
+	if _, err := Benchmark(context.Background(), New[int]("benchmark"), BenchmarkOptions{}); err == nil {
+		t.Errorf("expected an error without a control")
+	}
+}
@@ -0,0 +1,249 @@
+package scientist
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type BreakerState int
+
+const (
+	// BreakerClosed runs the candidate.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen skips the candidate until the cooldown has passed.
+	BreakerOpen
+	// BreakerHalfOpen runs the candidate once to probe whether it recovered.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerConfig configures when a candidate's circuit breaker trips. A
+// candidate fails when it returns an error, panics or times out.
+type BreakerConfig struct {
+	// FailureRate (0-1) of the runs in Window that trips the breaker. Zero
+	// disables it.
+	FailureRate float64
+	// MinSamples in Window before FailureRate applies. Defaults to 20.
+	MinSamples int
+	// ConsecutiveFailures within Window that trip the breaker. Zero
+	// disables it.
+	ConsecutiveFailures int
+	// Window of time outcomes are remembered for. Defaults to a minute.
+	Window time.Duration
+	// Cooldown before an open breaker lets a probe through. Defaults to 30
+	// seconds.
+	Cooldown time.Duration
+}
+
+// BreakerEvent is a change of state of a candidate's circuit breaker.
+type BreakerEvent struct {
+	Experiment string
+	Behavior   string
+	From       BreakerState
+	To         BreakerState
+	Reason     string
+	Time       time.Time
+}
+
+// Breaker stops running candidates that keep failing. An open breaker lets
+// a single run through after the cooldown: the breaker closes if it succeeds
+// and opens again if it fails.
+func (e *Experiment[T]) Breaker(cfg BreakerConfig) {
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 20
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+
+	onChange := func(BreakerEvent) {}
+	if e.breakers != nil {
+		onChange = e.breakers.onChange
+	}
+	e.breakers = &breakers{experiment: e.Name, cfg: cfg, onChange: onChange, states: make(map[string]*breaker)}
+}
+
+// OnBreakerChange sets a callback for breaker state changes. It is called
+// synchronously from the run that caused the change.
+func (e *Experiment[T]) OnBreakerChange(fn func(BreakerEvent)) {
+	if e.breakers == nil {
+		e.Breaker(BreakerConfig{})
+	}
+	e.breakers.onChange = fn
+}
+
+// BreakerState returns the state of a candidate's breaker.
+func (e *Experiment[T]) BreakerState(name string) BreakerState {
+	if e.breakers == nil {
+		return BreakerClosed
+	}
+	return e.breakers.state(name)
+}
+
+type breakers struct {
+	experiment string
+	cfg        BreakerConfig
+	onChange   func(BreakerEvent)
+	now        func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*breaker
+	probes uint64
+}
+
+type breaker struct {
+	state   BreakerState
+	changed time.Time
+	window  window
+	// probe identifies the run probing a half-open breaker.
+	probe uint64
+}
+
+func (bs *breakers) clock() time.Time {
+	if bs.now != nil {
+		return bs.now()
+	}
+	return time.Now()
+}
+
+func (bs *breakers) get(name string) *breaker {
+	b, ok := bs.states[name]
+	if !ok {
+		b = &breaker{}
+		bs.states[name] = b
+	}
+	return b
+}
+
+func (bs *breakers) state(name string) BreakerState {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	return bs.get(name).state
+}
+
+// available reports whether allow would let a candidate run, without
+// letting a probe through.
+func (bs *breakers) available(name string) bool {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b := bs.get(name)
+	return b.state == BreakerClosed || bs.clock().Sub(b.changed) >= bs.cfg.Cooldown
+}
+
+// allow reports whether a candidate may run, letting a probe through when an
+// open breaker has cooled down. probe identifies the probe, and is zero for
+// other runs. A probe that never reports back is replaced after another
+// cooldown.
+func (bs *breakers) allow(name string) (probe uint64, ok bool) {
+	bs.mu.Lock()
+	now := bs.clock()
+	b := bs.get(name)
+	if b.state == BreakerClosed {
+		bs.mu.Unlock()
+		return 0, true
+	}
+
+	if now.Sub(b.changed) < bs.cfg.Cooldown {
+		bs.mu.Unlock()
+		return 0, false
+	}
+
+	var event *BreakerEvent
+	if b.state == BreakerHalfOpen {
+		b.changed = now
+	} else {
+		event = bs.transition(name, b, BreakerHalfOpen, "probing after cooldown", now)
+	}
+	bs.probes++
+	b.probe = bs.probes
+	bs.mu.Unlock()
+
+	bs.emit(event)
+	return b.probe, true
+}
+
+// record records the outcome of a run of a candidate. Only the outcome of
+// the current probe closes or opens a half-open breaker.
+func (bs *breakers) record(name string, probe uint64, failed bool) {
+	bs.mu.Lock()
+	now := bs.clock()
+	b := bs.get(name)
+
+	var event *BreakerEvent
+	switch b.state {
+	case BreakerHalfOpen:
+		if probe == 0 || probe != b.probe {
+			break
+		}
+		if failed {
+			event = bs.transition(name, b, BreakerOpen, "probe failed", now)
+		} else {
+			event = bs.transition(name, b, BreakerClosed, "probe succeeded", now)
+		}
+	case BreakerClosed:
+		if reason := b.observe(bs.cfg, now, failed); reason != "" {
+			event = bs.transition(name, b, BreakerOpen, reason, now)
+		}
+	}
+	bs.mu.Unlock()
+
+	bs.emit(event)
+}
+
+// observe adds an outcome to a closed breaker and returns why it should trip.
+func (b *breaker) observe(cfg BreakerConfig, now time.Time, failed bool) string {
+	failures, total := b.window.add(now, cfg.Window, failed)
+
+	if consecutive := b.window.trailing(); cfg.ConsecutiveFailures > 0 && consecutive >= cfg.ConsecutiveFailures {
+		return fmt.Sprintf("%d consecutive failures", consecutive)
+	}
+
+	if cfg.FailureRate <= 0 || total < cfg.MinSamples {
+		return ""
+	}
+
+	rate := float64(failures) / float64(total)
+	if rate >= cfg.FailureRate {
+		return fmt.Sprintf("failure rate %.2f over %d runs", rate, total)
+	}
+	return ""
+}
+
+func (bs *breakers) transition(name string, b *breaker, to BreakerState, reason string, now time.Time) *BreakerEvent {
+	event := &BreakerEvent{
+		Experiment: bs.experiment,
+		Behavior:   name,
+		From:       b.state,
+		To:         to,
+		Reason:     reason,
+		Time:       now,
+	}
+
+	b.state = to
+	b.changed = now
+	b.probe = 0
+	b.window.reset()
+	return event
+}
+
+func (bs *breakers) emit(event *BreakerEvent) {
+	if event != nil {
+		bs.onChange(*event)
+	}
+}
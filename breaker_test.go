@@ -0,0 +1,259 @@
+package scientist
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	failing := true
+
+	e := New[int]("breaker")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	runs := 0
+	e.Try(func(ctx context.Context) (any, error) {
+		runs++
+		if failing {
+			panic("boom")
+		}
+		return 1, nil
+	})
+	e.ReportErrors(func(errs ...ResultError) {})
+
+	e.Breaker(BreakerConfig{ConsecutiveFailures: 3, Cooldown: time.Minute})
+	e.breakers.now = func() time.Time { return now }
+
+	var events []BreakerEvent
+	e.OnBreakerChange(func(event BreakerEvent) {
+		events = append(events, event)
+	})
+
+	for i := 0; i < 5; i++ {
+		e.Run(context.Background())
+	}
+
+	if runs != 3 {
+		t.Errorf("expected the breaker to stop the candidate after 3 runs, got %d", runs)
+	}
+	if state := e.BreakerState("candidate"); state != BreakerOpen {
+		t.Errorf("expected open breaker, got %s", state)
+	}
+
+	now = now.Add(2 * time.Minute)
+	e.Run(context.Background())
+	if runs != 4 || e.BreakerState("candidate") != BreakerOpen {
+		t.Errorf("expected a failed probe to open the breaker, got %d runs and %s", runs, e.BreakerState("candidate"))
+	}
+
+	failing = false
+	now = now.Add(2 * time.Minute)
+	e.Run(context.Background())
+	e.Run(context.Background())
+	if runs != 6 || e.BreakerState("candidate") != BreakerClosed {
+		t.Errorf("expected a successful probe to close the breaker, got %d runs and %s", runs, e.BreakerState("candidate"))
+	}
+
+	expected := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %v", len(expected), events)
+	}
+	for i, event := range events {
+		if event.To != expected[i] || event.Behavior != "candidate" || event.Experiment != "breaker" {
+			t.Errorf("unexpected event %d: %+v", i, event)
+		}
+	}
+	if events[0].Reason != "3 consecutive failures" {
+		t.Errorf("unexpected reason: %q", events[0].Reason)
+	}
+}
+
+func TestBreakerFailureRate(t *testing.T) {
+	now := time.Now()
+	bs := &breakers{
+		cfg:      BreakerConfig{FailureRate: 0.5, MinSamples: 4, Window: time.Minute},
+		onChange: func(BreakerEvent) {},
+		now:      func() time.Time { return now },
+		states:   make(map[string]*breaker),
+	}
+
+	for _, failed := range []bool{true, false, true} {
+		bs.record("api", 0, failed)
+	}
+	if bs.state("api") != BreakerClosed {
+		t.Errorf("expected breaker to wait for the minimum samples")
+	}
+
+	// older outcomes fall out of the window
+	now = now.Add(2 * time.Minute)
+	for _, failed := range []bool{false, false, false, true} {
+		bs.record("api", 0, failed)
+	}
+	if bs.state("api") != BreakerClosed {
+		t.Errorf("expected breaker to stay closed at a 25%% failure rate")
+	}
+
+	bs.record("api", 0, true)
+	bs.record("api", 0, true)
+	if bs.state("api") != BreakerOpen {
+		t.Errorf("expected breaker to open at a 50%% failure rate")
+	}
+}
+
+func TestBreakerTimeouts(t *testing.T) {
+	e := New[int]("breaker")
+	e.Synchronous = true
+	e.Timeout(time.Millisecond)
+	e.Breaker(BreakerConfig{ConsecutiveFailures: 1})
+	e.ReportErrors(func(errs ...ResultError) {})
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return 0, errors.New("slow")
+	})
+
+	e.Run(context.Background())
+	if state := e.BreakerState("candidate"); state != BreakerOpen {
+		t.Errorf("expected a timeout to open the breaker, got %s", state)
+	}
+}
+
+func TestBreakerWithFlagVariant(t *testing.T) {
+	flags := NewMemoryFlags()
+	flags.Set("breaker", Flag{Enabled: true, Variant: "api"})
+
+	e := New[int]("breaker")
+	e.Synchronous = true
+	e.Flags(flags)
+	e.Breaker(BreakerConfig{ConsecutiveFailures: 3})
+	e.ReportErrors(func(errs ...ResultError) {})
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	runs := 0
+	e.Behavior("api", func(ctx context.Context) (any, error) {
+		runs++
+		return 0, errors.New("down")
+	})
+
+	for i := 0; i < 10; i++ {
+		e.Run(context.Background())
+	}
+
+	if runs != 3 {
+		t.Errorf("expected the breaker to stop the variant after 3 runs, got %d", runs)
+	}
+	if state := e.BreakerState("api"); state != BreakerOpen {
+		t.Errorf("expected open breaker, got %s", state)
+	}
+}
+
+func TestBreakerWithSample(t *testing.T) {
+	now := time.Now()
+	e := New[int]("breaker")
+	e.Synchronous = true
+	e.Sample(1)
+	e.Breaker(BreakerConfig{ConsecutiveFailures: 1, Cooldown: time.Minute})
+	e.breakers.now = func() time.Time { return now }
+	e.ReportErrors(func(errs ...ResultError) {})
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	runs := make(map[string]int)
+	e.Behavior("api", func(ctx context.Context) (any, error) {
+		runs["api"]++
+		return 0, errors.New("down")
+	})
+	e.Behavior("raw-sql", func(ctx context.Context) (any, error) {
+		runs["raw-sql"]++
+		return 1, nil
+	})
+
+	for i := 0; i < 20; i++ {
+		e.Run(context.Background())
+	}
+	if runs["api"] != 1 || runs["raw-sql"] != 19 {
+		t.Errorf("expected the healthy candidate to run once the other tripped, got %v", runs)
+	}
+
+	// runs that sample the other candidate leave the probe alone
+	var events []BreakerState
+	e.OnBreakerChange(func(event BreakerEvent) {
+		events = append(events, event.To)
+	})
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 50; i++ {
+		e.Run(context.Background())
+	}
+	if runs["api"] != 2 || !slices.Equal(events, []BreakerState{BreakerHalfOpen, BreakerOpen}) {
+		t.Errorf("expected a single probe, got %v and events %v", runs, events)
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	now := time.Now()
+	bs := &breakers{
+		cfg:      BreakerConfig{ConsecutiveFailures: 1, Cooldown: time.Minute},
+		onChange: func(BreakerEvent) {},
+		now:      func() time.Time { return now },
+		states:   make(map[string]*breaker),
+	}
+
+	bs.record("api", 0, true)
+	now = now.Add(2 * time.Minute)
+	probe, ok := bs.allow("api")
+	if !ok || probe == 0 || bs.state("api") != BreakerHalfOpen {
+		t.Fatalf("expected a probe after the cooldown, got %d and %s", probe, bs.state("api"))
+	}
+
+	// runs let through before the breaker opened don't decide the probe
+	bs.record("api", 0, false)
+	bs.record("api", probe+1, true)
+	if bs.state("api") != BreakerHalfOpen {
+		t.Errorf("expected only the probe to close or open the breaker, got %s", bs.state("api"))
+	}
+	if _, ok := bs.allow("api"); ok {
+		t.Errorf("expected no second probe within the cooldown")
+	}
+
+	bs.record("api", probe, false)
+	if bs.state("api") != BreakerClosed {
+		t.Errorf("expected a successful probe to close the breaker, got %s", bs.state("api"))
+	}
+}
+
+func TestWindow(t *testing.T) {
+	var w window
+	now := time.Now()
+	for i := 0; i < 10000; i++ {
+		now = now.Add(time.Millisecond)
+		w.add(now, time.Second, i%4 == 0)
+	}
+
+	if len(w.buckets) > windowBuckets+1 {
+		t.Errorf("expected at most %d buckets, got %d", windowBuckets+1, len(w.buckets))
+	}
+	if failures, total := w.add(now, time.Second, true); total < 980 || total > 1001 || failures < total/4 || failures > total/4+2 {
+		t.Errorf("expected about a second of outcomes, got %d failures out of %d", failures, total)
+	}
+	if w.trailing() != 1 {
+		t.Errorf("expected a streak of 1 failure, got %d", w.trailing())
+	}
+
+	// outcomes and streaks older than the span are forgotten
+	w.add(now.Add(2*time.Second), time.Second, true)
+	if failures, total := w.add(now.Add(2*time.Second), time.Second, true); failures != 2 || total != 2 || w.trailing() != 2 {
+		t.Errorf("expected old outcomes to be forgotten, got %d failures out of %d and a streak of %d", failures, total, w.trailing())
+	}
+}
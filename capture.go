@@ -0,0 +1,234 @@
+package scientist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CaptureRecord is a line of a capture corpus: the inputs of a run and the
+// outcome of its control, as JSON.
+type CaptureRecord struct {
+	Experiment string            `json:"experiment"`
+	Time       time.Time         `json:"time"`
+	Context    map[string]string `json:"context,omitempty"`
+	Inputs     []json.RawMessage `json:"inputs"`
+	Value      json.RawMessage   `json:"value,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// captureBuffer is the number of runs a Capture holds while they wait to be
+// written.
+const captureBuffer = 1024
+
+// Capture appends the inputs and control outcomes of an experiment's runs to
+// a corpus file, one CaptureRecord per line, to replay them against
+// candidates offline with Replay1, Replay2, Replay3 or ReplayVariadic. Only
+// runs with inputs, like those of the Wrap adapters, are captured, whether or
+// not the candidates ran; runs of Wrap0 functions have none and are never
+// captured. It is safe to share between experiments and goroutines.
+//
+// Runs are encoded by Run, so the inputs and the control's value may be
+// changed once it returns, and written by a goroutine of the Capture, off the
+// path of Run. Runs that come in faster than they are written are dropped,
+// see Dropped.
+type Capture struct {
+	runs chan capturedRun
+	done chan struct{}
+
+	// mu guards closed against sending runs after Close.
+	mu     sync.RWMutex
+	closed bool
+
+	enablers []Enabler
+	dropped  atomic.Int64
+	full     atomic.Bool
+
+	// owned by the writing goroutine until done is closed
+	f        *os.File
+	size     int64
+	maxBytes int64
+	err      error
+}
+
+// capturedRun is an encoded run waiting to be written, with the callback to
+// report a failure to write it.
+type capturedRun struct {
+	line   []byte
+	report func(error)
+}
+
+// NewCapture appends to the corpus at path, creating it if needed. Once a
+// record would grow the file over maxBytes, runs are no longer captured; zero
+// doesn't limit it. When enablers are given, only the runs they all enable are
+// captured, like Percent(1) for a sample of one percent.
+func NewCapture(path string, maxBytes int64, enablers ...Enabler) (*Capture, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	c := &Capture{
+		runs:     make(chan capturedRun, captureBuffer),
+		done:     make(chan struct{}),
+		enablers: enablers,
+		f:        f,
+		size:     info.Size(),
+		maxBytes: maxBytes,
+	}
+	c.full.Store(maxBytes > 0 && c.size >= maxBytes)
+	go c.write()
+	return c, nil
+}
+
+// Close writes the runs waiting to be written and closes the corpus file.
+// Runs are no longer captured afterwards.
+func (c *Capture) Close() error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.runs)
+	}
+	c.mu.Unlock()
+
+	<-c.done
+	return c.err
+}
+
+// Full reports whether the corpus reached its size limit.
+func (c *Capture) Full() bool {
+	return c.full.Load()
+}
+
+// Dropped returns the number of runs that weren't captured because too many
+// were waiting to be written.
+func (c *Capture) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// sampled reports whether a run is captured, unless it's left out of the
+// sample or the corpus is full or closed.
+func (c *Capture) sampled(ctx context.Context) (bool, error) {
+	if c.Full() {
+		return false, nil
+	}
+	for _, en := range c.enablers {
+		if ok, err := en(ctx); err != nil || !ok {
+			return false, err
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.closed, nil
+}
+
+// record queues an encoded run to be written.
+func (c *Capture) record(run capturedRun) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.runs <- run:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// write writes the queued runs until the Capture is closed.
+func (c *Capture) write() {
+	defer close(c.done)
+
+	for run := range c.runs {
+		if c.full.Load() {
+			continue
+		}
+		if err := c.writeLine(run.line); err != nil {
+			run.report(err)
+		}
+	}
+	c.err = c.f.Close()
+}
+
+func (c *Capture) writeLine(line []byte) error {
+	if c.maxBytes > 0 && c.size+int64(len(line)) > c.maxBytes {
+		c.full.Store(true)
+		return nil
+	}
+	n, err := c.f.Write(line)
+	c.size += int64(n)
+	return err
+}
+
+// encodeRun encodes a run as a line of the corpus. It runs on the goroutine
+// of Run, before the caller can change the inputs or the value.
+func encodeRun(rec CaptureRecord, inputs []any, value any, err error) ([]byte, error) {
+	rec.Inputs = make([]json.RawMessage, len(inputs))
+	for i, input := range inputs {
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		rec.Inputs[i] = raw
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	} else {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("control value: %w", err)
+		}
+		rec.Value = raw
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// Capture records the inputs and control outcome of runs to c.
+func (e *Experiment[T]) Capture(c *Capture) {
+	e.capture = c
+}
+
+// captureRun queues a run to be written by the experiment's capture,
+// reporting failures as "capture" errors.
+func (e *Experiment[T]) captureRun(ctx context.Context, attrs map[string]string, inputs []any, control *Observation[T, T]) {
+	if e.capture == nil || inputs == nil {
+		return
+	}
+
+	ok, err := e.capture.sampled(ctx)
+	if err != nil || !ok {
+		if err != nil {
+			e.reportError(ctx, "capture", err)
+		}
+		return
+	}
+
+	rec := CaptureRecord{Experiment: e.Name, Time: time.Now(), Context: attrs}
+	line, err := encodeRun(rec, inputs, control.Value, control.Err)
+	if err != nil {
+		e.reportError(ctx, "capture", err)
+		return
+	}
+
+	reportCtx := context.WithoutCancel(ctx)
+	e.capture.record(capturedRun{line: line, report: func(err error) {
+		e.reportError(reportCtx, "capture", err)
+	}})
+}
@@ -0,0 +1,180 @@
+package scientist
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func readCorpus(t *testing.T, path string) []CaptureRecord {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []CaptureRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec CaptureRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestCapture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	capture, err := NewCapture(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	control := func(ctx context.Context, id int64) (*user, error) {
+		if id == 0 {
+			return nil, errors.New("not found")
+		}
+		return &user{ID: id, Name: "hubot"}, nil
+	}
+	// the candidates don't need to run to capture the control
+	find := Wrap1("find-user", control, control, WithCapture(capture), WithContext(map[string]string{"host": "a"}), WithRunIf(func() (bool, error) {
+		return false, nil
+	}))
+	for _, id := range []int64{1, 0} {
+		find(context.Background(), id)
+	}
+
+	e := New[int]("no-inputs", WithCapture(capture))
+	e.Use(func(ctx context.Context) (int, error) { return 1, nil })
+	e.Run(context.Background())
+
+	if err := capture.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := readCorpus(t, path)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if rec := records[0]; rec.Experiment != "find-user" || string(rec.Inputs[0]) != "1" || string(rec.Value) != `{"ID":1,"Name":"hubot"}` || rec.Error != "" || rec.Context["host"] != "a" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	if rec := records[1]; string(rec.Inputs[0]) != "0" || rec.Value != nil || rec.Error != "not found" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
+
+func TestCaptureLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+
+	sampled, err := NewCapture(path, 0, Percent(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	twice := func(ctx context.Context, n int) (int, error) {
+		return n * 2, nil
+	}
+	double := Wrap1("double", twice, twice, WithCapture(sampled))
+	double(context.Background(), 1)
+	sampled.Close()
+
+	if records := readCorpus(t, path); len(records) != 0 {
+		t.Errorf("expected no records out of the sample, got %d", len(records))
+	}
+
+	capped, err := NewCapture(path, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	double = Wrap1("double", twice, twice, WithCapture(capped))
+	for i := 0; i < 10; i++ {
+		double(context.Background(), i)
+	}
+	capped.Close()
+	if !capped.Full() {
+		t.Errorf("expected the corpus to be full")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 200 {
+		t.Errorf("expected at most 200 bytes, got %d", info.Size())
+	}
+	if records := readCorpus(t, path); len(records) == 0 || len(records) == 10 {
+		t.Errorf("expected some records, got %d", len(records))
+	}
+}
+
+func TestCaptureErrors(t *testing.T) {
+	capture, err := NewCapture(filepath.Join(t.TempDir(), "corpus.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var reported []ResultError
+	send := func(ctx context.Context, ch chan int) (int, error) {
+		return 1, nil
+	}
+	wrapped := Wrap1("send", send, send, WithCapture(capture), WithErrorReporter(func(errs ...ResultError) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, errs...)
+	}))
+
+	wrapped(context.Background(), make(chan int))
+	if err := capture.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// runs after Close are left out
+	wrapped(context.Background(), make(chan int))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || reported[0].Operation != "capture" || reported[0].Experiment != "send" {
+		t.Errorf("expected the unencodable input to be reported, got %v", reported)
+	}
+}
+
+// TestCaptureMutation checks that the caller may change the inputs and the
+// control's value once the run returns. Run it with -race.
+func TestCaptureMutation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	capture, err := NewCapture(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(ctx context.Context, words map[string]int) (map[string]int, error) {
+		return map[string]int{"total": len(words)}, nil
+	}
+	// the candidates would share the values too, so only the control runs
+	counted := Wrap1("count", count, count, WithCapture(capture), WithRunIf(func() (bool, error) {
+		return false, nil
+	}))
+	for i := 0; i < 100; i++ {
+		words := map[string]int{"a": 1}
+		value, _ := counted(context.Background(), words)
+		words["b"] = 2
+		value["total"] = -1
+	}
+	if err := capture.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rec := range readCorpus(t, path) {
+		if string(rec.Inputs[0]) != `{"a":1}` || string(rec.Value) != `{"total":1}` {
+			t.Fatalf("expected the run as it returned, got %+v", rec)
+		}
+	}
+}
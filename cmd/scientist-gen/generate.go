@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const scientistPath = "github.com/freshworks/go-scientist"
+
+// generator writes the experiment wrapper of an interface.
+type generator struct {
+	pkg *types.Package
+
+	// imports maps package paths to the names they are imported as.
+	imports map[string]string
+	taken   map[string]bool
+}
+
+type method struct {
+	Name     string
+	Field    string
+	Ctx      bool
+	Params   []param
+	Variadic bool
+	Results  []string
+	Err      bool
+	// Value is the type of the method's experiment.
+	Value string
+}
+
+type param struct {
+	Name string
+	Type string
+}
+
+// generate returns the source of structName, which implements the interface
+// typeName of pkg by running every method as an experiment.
+func generate(pkg *types.Package, typeName, structName string) ([]byte, error) {
+	obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in %s", typeName, pkg.Path())
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a named type", typeName)
+	}
+	if named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("%s: generic interfaces are not supported", typeName)
+	}
+
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an interface", typeName)
+	}
+	if iface.NumMethods() == 0 {
+		return nil, fmt.Errorf("%s has no methods", typeName)
+	}
+
+	g := &generator{
+		pkg:     pkg,
+		imports: map[string]string{"context": "context", scientistPath: "scientist"},
+		taken:   map[string]bool{"context": true, "scientist": true},
+	}
+
+	methods := make([]method, 0, iface.NumMethods())
+	for i := 0; i < iface.NumMethods(); i++ {
+		m, err := g.method(structName, iface.Method(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", typeName, iface.Method(i).Name(), err)
+		}
+		methods = append(methods, m)
+	}
+
+	var b bytes.Buffer
+	g.write(&b, typeName, structName, methods)
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, b.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) method(structName string, fn *types.Func) (method, error) {
+	if !fn.Exported() && fn.Pkg() != g.pkg {
+		return method{}, fmt.Errorf("unexported method of another package")
+	}
+
+	sig := fn.Type().(*types.Signature)
+	m := method{
+		Name:     fn.Name(),
+		Field:    lowerFirst(fn.Name()) + "Runner",
+		Variadic: sig.Variadic(),
+	}
+
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		p := params.At(i)
+		if i == 0 && isContext(p.Type()) {
+			m.Ctx = true
+			continue
+		}
+
+		typ := g.typeString(p.Type())
+		if m.Variadic && i == params.Len()-1 {
+			typ = "..." + g.typeString(p.Type().(*types.Slice).Elem())
+		}
+		m.Params = append(m.Params, param{Name: g.paramName(p.Name(), i), Type: typ})
+	}
+
+	results := sig.Results()
+	for i := 0; i < results.Len(); i++ {
+		r := results.At(i)
+		if i == results.Len()-1 && isError(r.Type()) {
+			m.Err = true
+			continue
+		}
+		m.Results = append(m.Results, g.typeString(r.Type()))
+	}
+
+	switch len(m.Results) {
+	case 0:
+		m.Value = "struct{}"
+	case 1:
+		m.Value = m.Results[0]
+	default:
+		m.Value = lowerFirst(structName) + m.Name + "Result"
+	}
+	return m, nil
+}
+
+func (g *generator) write(b *bytes.Buffer, typeName, structName string, methods []method) {
+	options := typeName + "MethodOptions"
+	constructor := "New" + upperFirst(structName)
+	if !token.IsExported(structName) {
+		constructor = "new" + upperFirst(structName)
+	}
+
+	fmt.Fprintf(b, "// Code generated by scientist-gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(b, "package %s\n\n", g.pkg.Name())
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if isStd(paths[i]) != isStd(paths[j]) {
+			return isStd(paths[i])
+		}
+		return paths[i] < paths[j]
+	})
+	fmt.Fprintf(b, "import (\n")
+	std := true
+	for _, path := range paths {
+		if std && !isStd(path) {
+			// sorted standard library paths come first
+			std = false
+			fmt.Fprintf(b, "\n")
+		}
+		if name := g.imports[path]; name != defaultName(path) {
+			fmt.Fprintf(b, "\t%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(b, "\t%q\n", path)
+		}
+	}
+	fmt.Fprintf(b, ")\n\n")
+
+	fmt.Fprintf(b, "// %s implements %s by running every method as an experiment.\n", structName, typeName)
+	fmt.Fprintf(b, "type %s struct {\n", structName)
+	for _, m := range methods {
+		fmt.Fprintf(b, "\t%s *scientist.Runner[%s]\n", m.Field, m.Value)
+	}
+	fmt.Fprintf(b, "}\n\n")
+
+	fmt.Fprintf(b, "// %s configures the experiments of single methods.\n", options)
+	fmt.Fprintf(b, "type %s struct {\n", options)
+	for _, m := range methods {
+		fmt.Fprintf(b, "\t%s []scientist.Option\n", m.Name)
+	}
+	fmt.Fprintf(b, "}\n\n")
+
+	for _, m := range methods {
+		if len(m.Results) < 2 {
+			continue
+		}
+		fmt.Fprintf(b, "type %s struct {\n", m.Value)
+		for i, r := range m.Results {
+			fmt.Fprintf(b, "\tR%d %s\n", i, r)
+		}
+		fmt.Fprintf(b, "}\n\n")
+	}
+
+	fmt.Fprintf(b, "// %s runs control and candidate side by side. The experiment\n", constructor)
+	fmt.Fprintf(b, "// of a method is named \"<name>.<Method>\" and is configured by opts,\n")
+	fmt.Fprintf(b, "// then by the method's options.\n")
+	fmt.Fprintf(b, "func %s(name string, control, candidate %s, methods %s, opts ...scientist.Option) *%s {\n", constructor, typeName, options, structName)
+	fmt.Fprintf(b, "\ts := &%s{}\n", structName)
+	for _, m := range methods {
+		fmt.Fprintf(b, "\t{\n")
+		fmt.Fprintf(b, "\t\te := scientist.New[%s](name+%q, scientist.Options(opts...), scientist.Options(methods.%s...))\n", m.Value, "."+m.Name, m.Name)
+		fmt.Fprintf(b, "\t\te.Use(func(ctx context.Context) (%s, error) {\n", m.Value)
+		g.writeCall(b, m, "control")
+		fmt.Fprintf(b, "\t\t})\n")
+		fmt.Fprintf(b, "\t\te.Try(func(ctx context.Context) (any, error) {\n")
+		g.writeCall(b, m, "candidate")
+		fmt.Fprintf(b, "\t\t})\n")
+		fmt.Fprintf(b, "\t\ts.%s, _ = e.Build()\n", m.Field)
+		fmt.Fprintf(b, "\t}\n")
+	}
+	fmt.Fprintf(b, "\treturn s\n}\n\n")
+
+	fmt.Fprintf(b, "var _ %s = (*%s)(nil)\n", typeName, structName)
+
+	for _, m := range methods {
+		fmt.Fprintf(b, "\n")
+		g.writeMethod(b, structName, m)
+	}
+}
+
+// writeCall writes the body of a behavior calling the method of impl with
+// the inputs of the run.
+func (g *generator) writeCall(b *bytes.Buffer, m method, impl string) {
+	args := make([]string, 0, len(m.Params)+1)
+	if m.Ctx {
+		args = append(args, "ctx")
+	}
+	for i, p := range m.Params {
+		typ, spread := p.Type, ""
+		if strings.HasPrefix(typ, "...") {
+			typ, spread = "[]"+typ[3:], "..."
+		}
+		args = append(args, fmt.Sprintf("scientist.As[%s](in[%d])%s", typ, i, spread))
+	}
+
+	if len(m.Params) > 0 {
+		fmt.Fprintf(b, "\t\t\tin := scientist.RunInputs(ctx)\n")
+	}
+	call := fmt.Sprintf("%s.%s(%s)", impl, m.Name, strings.Join(args, ", "))
+
+	switch {
+	case len(m.Results) == 0 && m.Err:
+		fmt.Fprintf(b, "\t\t\treturn struct{}{}, %s\n", call)
+	case len(m.Results) == 0:
+		fmt.Fprintf(b, "\t\t\t%s\n\t\t\treturn struct{}{}, nil\n", call)
+	case len(m.Results) == 1 && m.Err:
+		fmt.Fprintf(b, "\t\t\treturn %s\n", call)
+	case len(m.Results) == 1:
+		fmt.Fprintf(b, "\t\t\treturn %s, nil\n", call)
+	default:
+		vars := make([]string, len(m.Results))
+		for i := range vars {
+			vars[i] = fmt.Sprintf("r%d", i)
+		}
+		err := "nil"
+		lhs := strings.Join(vars, ", ")
+		if m.Err {
+			lhs += ", err"
+			err = "err"
+		}
+		fmt.Fprintf(b, "\t\t\t%s := %s\n", lhs, call)
+		fmt.Fprintf(b, "\t\t\treturn %s{%s}, %s\n", m.Value, strings.Join(vars, ", "), err)
+	}
+}
+
+func (g *generator) writeMethod(b *bytes.Buffer, structName string, m method) {
+	params := make([]string, 0, len(m.Params)+1)
+	ctx := "context.Background()"
+	if m.Ctx {
+		params = append(params, "ctx context.Context")
+		ctx = "ctx"
+	}
+	inputs := make([]string, 0, len(m.Params))
+	for _, p := range m.Params {
+		params = append(params, p.Name+" "+p.Type)
+		inputs = append(inputs, p.Name)
+	}
+
+	results := append([]string(nil), m.Results...)
+	if m.Err {
+		results = append(results, "error")
+	}
+	signature := strings.Join(results, ", ")
+	if len(results) > 1 {
+		signature = "(" + signature + ")"
+	}
+
+	fmt.Fprintf(b, "func (s *%s) %s(%s) %s {\n", structName, m.Name, strings.Join(params, ", "), signature)
+
+	run := fmt.Sprintf("s.%s.Run(%s)", m.Field, ctx)
+	if len(inputs) > 0 {
+		run = fmt.Sprintf("s.%s.Run(%s, scientist.Inputs(%s))", m.Field, ctx, strings.Join(inputs, ", "))
+	}
+
+	switch {
+	case len(m.Results) == 1 && m.Err:
+		fmt.Fprintf(b, "\treturn %s\n}\n", run)
+		return
+	case len(m.Results) == 0 && m.Err:
+		fmt.Fprintf(b, "\t_, err := %s\n", run)
+	case len(m.Results) == 0:
+		fmt.Fprintf(b, "\tif _, err := %s; err != nil {\n\t\tpanic(err)\n\t}\n", run)
+	case m.Err:
+		fmt.Fprintf(b, "\tv, err := %s\n", run)
+	default:
+		fmt.Fprintf(b, "\tv, err := %s\n\tif err != nil {\n\t\tpanic(err)\n\t}\n", run)
+	}
+
+	var returns []string
+	switch len(m.Results) {
+	case 0:
+	case 1:
+		returns = append(returns, "v")
+	default:
+		for i := range m.Results {
+			returns = append(returns, fmt.Sprintf("v.R%d", i))
+		}
+	}
+	if m.Err {
+		returns = append(returns, "err")
+	}
+	if len(returns) > 0 {
+		fmt.Fprintf(b, "\treturn %s\n", strings.Join(returns, ", "))
+	}
+	fmt.Fprintf(b, "}\n")
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		return g.importName(p)
+	})
+}
+
+// importName returns the name a package is imported as, picking a new one
+// when its name is taken.
+func (g *generator) importName(p *types.Package) string {
+	if name, ok := g.imports[p.Path()]; ok {
+		return name
+	}
+
+	name := p.Name()
+	for i := 2; g.taken[name]; i++ {
+		name = p.Name() + strconv.Itoa(i)
+	}
+	g.imports[p.Path()] = name
+	g.taken[name] = true
+	return name
+}
+
+// paramName keeps a parameter's name unless it's missing or clashes with the
+// names the generated code uses.
+func (g *generator) paramName(name string, i int) string {
+	switch name {
+	case "", "_", "s", "v", "err", "ctx", "in", "context", "scientist":
+		return fmt.Sprintf("a%d", i)
+	}
+	return name
+}
+
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+func isStd(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+func defaultName(path string) string {
+	if path == scientistPath {
+		return "scientist"
+	}
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerate(t *testing.T) {
+	golden := filepath.Join("testdata", "store", "store_scientist.go")
+	pkg, err := load(filepath.Join("testdata", "store"), golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generate(pkg.Types, "Store", "StoreExperiment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if err := os.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, expected) {
+		t.Errorf("generated code differs from %s, run go test -update:\n%s", golden, src)
+	}
+}
+
+// TestGeneratedCode runs the tests of the golden package against the
+// generated code.
+func TestGeneratedCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+
+	out, err := exec.Command("go", "test", "./testdata/store").CombinedOutput()
+	if err != nil {
+		t.Errorf("go test ./testdata/store: %v\n%s", err, out)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	pkg := check(t, `package p
+type Struct struct{}
+type Empty interface{}
+type Generic[T any] interface{ Get() T }
+`)
+
+	for name, expected := range map[string]string{
+		"Missing": "not found",
+		"Struct":  "not an interface",
+		"Empty":   "no methods",
+		"Generic": "generic interfaces",
+	} {
+		_, err := generate(pkg, name, name+"Experiment")
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, expected, err)
+		}
+	}
+}
+
+func TestGenerateUnexported(t *testing.T) {
+	pkg := check(t, `package p
+type store interface {
+	get(id int) (string, error)
+	Put(string, string)
+}
+`)
+
+	src, err := generate(pkg, "store", "storeExperiment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"func newStoreExperiment(name string, control, candidate store, methods storeMethodOptions",
+		"func (s *storeExperiment) get(id int) (string, error) {",
+		"func (s *storeExperiment) Put(a0 string, a1 string) {",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in:\n%s", expected, src)
+		}
+	}
+}
+
+func check(t *testing.T, src string) *types.Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
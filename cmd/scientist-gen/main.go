@@ -0,0 +1,103 @@
+// Command scientist-gen generates a type that implements an interface by
+// running every method as an experiment, with one implementation as the
+// control and another as the candidate. Use it with go generate:
+//
+//	//go:generate go run github.com/freshworks/go-scientist/cmd/scientist-gen -type Store
+//
+// This writes store_scientist.go with a StoreExperiment type, a
+// NewStoreExperiment constructor and StoreMethodOptions to configure the
+// experiments of single methods.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the interface")
+	structName := flag.String("name", "", "name of the generated type (default <type>Experiment)")
+	output := flag.String("output", "", "output file (default <type>_scientist.go in the package directory)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: scientist-gen -type Interface [-name Type] [-output file] [package directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeName == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	if *structName == "" {
+		*structName = *typeName + "Experiment"
+	}
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(*typeName)+"_scientist.go")
+	}
+
+	if err := run(dir, *typeName, *structName, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "scientist-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, typeName, structName, output string) error {
+	pkg, err := load(dir, output)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg.Types, typeName, structName)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+// load type checks the package in dir, leaving out the output file so a
+// stale one doesn't break the build.
+func load(dir, output string) (*packages.Package, error) {
+	output, err := filepath.Abs(output)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			f, err := parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
+			if filename == output && f != nil {
+				f.Decls, f.Imports = nil, nil
+			}
+			return f, err
+		},
+	}
+
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, got %d", dir, len(pkgs))
+	}
+
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, pkg.Errors[0]
+	}
+	return pkg, nil
+}
@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+//go:generate go run github.com/freshworks/go-scientist/cmd/scientist-gen -type Store
+
+type User struct {
+	ID   int64
+	Name string
+}
+
+type Pinger interface {
+	Ping()
+}
+
+type Store interface {
+	Pinger
+	Get(ctx context.Context, id int64) (*User, error)
+	List(ctx context.Context, since time.Duration) ([]*User, int, error)
+	Names(prefix string) []string
+	Delete(ctx context.Context, ids ...int64) error
+	Export(ctx context.Context, w io.Writer, _ bool) error
+}
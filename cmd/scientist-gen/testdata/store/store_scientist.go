@@ -0,0 +1,153 @@
+// Code generated by scientist-gen; DO NOT EDIT.
+
+package store
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/freshworks/go-scientist"
+)
+
+// StoreExperiment implements Store by running every method as an experiment.
+type StoreExperiment struct {
+	deleteRunner *scientist.Runner[struct{}]
+	exportRunner *scientist.Runner[struct{}]
+	getRunner    *scientist.Runner[*User]
+	listRunner   *scientist.Runner[storeExperimentListResult]
+	namesRunner  *scientist.Runner[[]string]
+	pingRunner   *scientist.Runner[struct{}]
+}
+
+// StoreMethodOptions configures the experiments of single methods.
+type StoreMethodOptions struct {
+	Delete []scientist.Option
+	Export []scientist.Option
+	Get    []scientist.Option
+	List   []scientist.Option
+	Names  []scientist.Option
+	Ping   []scientist.Option
+}
+
+type storeExperimentListResult struct {
+	R0 []*User
+	R1 int
+}
+
+// NewStoreExperiment runs control and candidate side by side. The experiment
+// of a method is named "<name>.<Method>" and is configured by opts,
+// then by the method's options.
+func NewStoreExperiment(name string, control, candidate Store, methods StoreMethodOptions, opts ...scientist.Option) *StoreExperiment {
+	s := &StoreExperiment{}
+	{
+		e := scientist.New[struct{}](name+".Delete", scientist.Options(opts...), scientist.Options(methods.Delete...))
+		e.Use(func(ctx context.Context) (struct{}, error) {
+			in := scientist.RunInputs(ctx)
+			return struct{}{}, control.Delete(ctx, scientist.As[[]int64](in[0])...)
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			in := scientist.RunInputs(ctx)
+			return struct{}{}, candidate.Delete(ctx, scientist.As[[]int64](in[0])...)
+		})
+		s.deleteRunner, _ = e.Build()
+	}
+	{
+		e := scientist.New[struct{}](name+".Export", scientist.Options(opts...), scientist.Options(methods.Export...))
+		e.Use(func(ctx context.Context) (struct{}, error) {
+			in := scientist.RunInputs(ctx)
+			return struct{}{}, control.Export(ctx, scientist.As[io.Writer](in[0]), scientist.As[bool](in[1]))
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			in := scientist.RunInputs(ctx)
+			return struct{}{}, candidate.Export(ctx, scientist.As[io.Writer](in[0]), scientist.As[bool](in[1]))
+		})
+		s.exportRunner, _ = e.Build()
+	}
+	{
+		e := scientist.New[*User](name+".Get", scientist.Options(opts...), scientist.Options(methods.Get...))
+		e.Use(func(ctx context.Context) (*User, error) {
+			in := scientist.RunInputs(ctx)
+			return control.Get(ctx, scientist.As[int64](in[0]))
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			in := scientist.RunInputs(ctx)
+			return candidate.Get(ctx, scientist.As[int64](in[0]))
+		})
+		s.getRunner, _ = e.Build()
+	}
+	{
+		e := scientist.New[storeExperimentListResult](name+".List", scientist.Options(opts...), scientist.Options(methods.List...))
+		e.Use(func(ctx context.Context) (storeExperimentListResult, error) {
+			in := scientist.RunInputs(ctx)
+			r0, r1, err := control.List(ctx, scientist.As[time.Duration](in[0]))
+			return storeExperimentListResult{r0, r1}, err
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			in := scientist.RunInputs(ctx)
+			r0, r1, err := candidate.List(ctx, scientist.As[time.Duration](in[0]))
+			return storeExperimentListResult{r0, r1}, err
+		})
+		s.listRunner, _ = e.Build()
+	}
+	{
+		e := scientist.New[[]string](name+".Names", scientist.Options(opts...), scientist.Options(methods.Names...))
+		e.Use(func(ctx context.Context) ([]string, error) {
+			in := scientist.RunInputs(ctx)
+			return control.Names(scientist.As[string](in[0])), nil
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			in := scientist.RunInputs(ctx)
+			return candidate.Names(scientist.As[string](in[0])), nil
+		})
+		s.namesRunner, _ = e.Build()
+	}
+	{
+		e := scientist.New[struct{}](name+".Ping", scientist.Options(opts...), scientist.Options(methods.Ping...))
+		e.Use(func(ctx context.Context) (struct{}, error) {
+			control.Ping()
+			return struct{}{}, nil
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			candidate.Ping()
+			return struct{}{}, nil
+		})
+		s.pingRunner, _ = e.Build()
+	}
+	return s
+}
+
+var _ Store = (*StoreExperiment)(nil)
+
+func (s *StoreExperiment) Delete(ctx context.Context, ids ...int64) error {
+	_, err := s.deleteRunner.Run(ctx, scientist.Inputs(ids))
+	return err
+}
+
+func (s *StoreExperiment) Export(ctx context.Context, w io.Writer, a2 bool) error {
+	_, err := s.exportRunner.Run(ctx, scientist.Inputs(w, a2))
+	return err
+}
+
+func (s *StoreExperiment) Get(ctx context.Context, id int64) (*User, error) {
+	return s.getRunner.Run(ctx, scientist.Inputs(id))
+}
+
+func (s *StoreExperiment) List(ctx context.Context, since time.Duration) ([]*User, int, error) {
+	v, err := s.listRunner.Run(ctx, scientist.Inputs(since))
+	return v.R0, v.R1, err
+}
+
+func (s *StoreExperiment) Names(prefix string) []string {
+	v, err := s.namesRunner.Run(context.Background(), scientist.Inputs(prefix))
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (s *StoreExperiment) Ping() {
+	if _, err := s.pingRunner.Run(context.Background()); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/freshworks/go-scientist"
+)
+
+type memoryStore map[int64]*User
+
+func (s memoryStore) Ping() {}
+
+func (s memoryStore) Get(ctx context.Context, id int64) (*User, error) {
+	if u, ok := s[id]; ok {
+		return u, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (s memoryStore) List(ctx context.Context, since time.Duration) ([]*User, int, error) {
+	return nil, len(s), nil
+}
+
+func (s memoryStore) Names(prefix string) []string {
+	return []string{prefix}
+}
+
+func (s memoryStore) Delete(ctx context.Context, ids ...int64) error {
+	return nil
+}
+
+func (s memoryStore) Export(ctx context.Context, w io.Writer, _ bool) error {
+	return nil
+}
+
+func TestStoreExperiment(t *testing.T) {
+	old := memoryStore{1: {1, "hubot"}}
+	new := memoryStore{1: {1, "hubot"}, 2: {2, "octocat"}}
+
+	var result *scientist.Result[*User]
+	s := NewStoreExperiment("store", old, new, StoreMethodOptions{
+		Get: []scientist.Option{scientist.WithPublisher(func(r *scientist.Result[*User]) error {
+			result = r
+			return nil
+		})},
+	}, scientist.WithSynchronous(true))
+
+	u, err := s.Get(context.Background(), 2)
+	if u != nil || err == nil {
+		t.Errorf("expected the control's error, got %+v, %v", u, err)
+	}
+	if result == nil || !result.IsMismatched() || result.Inputs[0] != int64(2) {
+		t.Fatalf("expected a mismatch for Get(2), got %+v", result)
+	}
+	if result.Experiment.Name != "store.Get" {
+		t.Errorf("unexpected experiment name %q", result.Experiment.Name)
+	}
+
+	if _, n, err := s.List(context.Background(), time.Hour); n != 1 || err != nil {
+		t.Errorf("unexpected List result: %d, %v", n, err)
+	}
+	if names := s.Names("hu"); len(names) != 1 || names[0] != "hu" {
+		t.Errorf("unexpected names: %v", names)
+	}
+	if err := s.Delete(context.Background(), 1, 2); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	s.Ping()
+}
@@ -0,0 +1,17 @@
+// Command scientist-vet reports common mistakes with experiments. Run it on
+// its own or through go vet:
+//
+//	go install github.com/freshworks/go-scientist/cmd/scientist-vet@latest
+//	go vet -vettool=$(which scientist-vet) ./...
+//
+// See package scientistcheck for the checks.
+package main
+
+import (
+	"github.com/freshworks/go-scientist/scientistcheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(scientistcheck.Analyzer)
+}
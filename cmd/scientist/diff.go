@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/freshworks/go-scientist"
+)
+
+// maxDiffs bounds the differences kept for an example diff.
+const maxDiffs = 20
+
+// difference is a place where a candidate's value differs from the
+// control's.
+type difference struct {
+	path               string
+	control, candidate string
+}
+
+type diff []difference
+
+// diffObservations compares the JSON values, or errors, of the control and a
+// candidate.
+func diffObservations(control, candidate scientist.ObservationRecord) diff {
+	if control.Error != "" || candidate.Error != "" {
+		return diff{{path: "error", control: errorString(control.Error), candidate: errorString(candidate.Error)}}
+	}
+
+	var d diff
+	d.values("", decode(control.Value), decode(candidate.Value))
+	if len(d) == 0 {
+		// equal once encoded, but the comparator disagreed
+		d = diff{{path: "value", control: string(control.Value), candidate: string(candidate.Value)}}
+	}
+	return d
+}
+
+func errorString(err string) string {
+	if err == "" {
+		return "no error"
+	}
+	return strconv.Quote(err)
+}
+
+func decode(raw json.RawMessage) any {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return string(raw)
+	}
+	return v
+}
+
+func (d *diff) values(path string, a, b any) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			keys := make([]string, 0, len(a)+len(b))
+			for k := range a {
+				keys = append(keys, k)
+			}
+			for k := range b {
+				if _, ok := a[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				av, aok := a[k]
+				bv, bok := b[k]
+				switch {
+				case !aok:
+					d.add(path+field(k), "<missing>", encode(bv))
+				case !bok:
+					d.add(path+field(k), encode(av), "<missing>")
+				default:
+					d.values(path+field(k), av, bv)
+				}
+			}
+			return
+		}
+
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				elem := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(a):
+					d.add(elem, "<missing>", encode(b[i]))
+				case i >= len(b):
+					d.add(elem, encode(a[i]), "<missing>")
+				default:
+					d.values(elem, a[i], b[i])
+				}
+			}
+			return
+		}
+	}
+
+	if ae, be := encode(a), encode(b); ae != be {
+		d.add(path, ae, be)
+	}
+}
+
+func (d *diff) add(path, control, candidate string) {
+	if path == "" {
+		path = "value"
+	}
+	*d = append(*d, difference{path: path, control: control, candidate: candidate})
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func field(k string) string {
+	if identifier.MatchString(k) {
+		return "." + k
+	}
+	return "[" + strconv.Quote(k) + "]"
+}
+
+func encode(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+var index = regexp.MustCompile(`\[\d+\]`)
+
+// paths returns the places that differ, without slice indexes and
+// duplicates.
+func (d diff) paths() []string {
+	seen := map[string]bool{}
+	var paths []string
+	for _, diff := range d {
+		p := index.ReplaceAllString(diff.path, "[]")
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+// key identifies the kind of the difference: the paths, and for errors the
+// messages without the numbers they often contain.
+func (d diff) key() string {
+	if len(d) == 1 && d[0].path == "error" {
+		return "error\x00" + digits.ReplaceAllString(d[0].control, "#") + "\x00" + digits.ReplaceAllString(d[0].candidate, "#")
+	}
+	return strings.Join(d.paths(), "\x00")
+}
+
+func fingerprintID(key string) string {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// String formats the differences like scientisttest.Diff.
+func (d diff) String() string {
+	var b strings.Builder
+	for i, diff := range d {
+		if i == maxDiffs {
+			fmt.Fprintf(&b, "... %d more\n", len(d)-maxDiffs)
+			break
+		}
+		fmt.Fprintf(&b, "%s:\n  - %s\n  + %s\n", diff.path, diff.control, diff.candidate)
+	}
+	return b.String()
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": percent,
+	"time":    func(t time.Time) string { return t.Format(time.RFC3339) },
+	"short":   short,
+}).Parse(reportHTML))
+
+// histogramBins is the number of bins of the latency histograms.
+const histogramBins = 12
+
+// report is the data of an experiment's HTML report.
+type report struct {
+	*summary
+	Histogram    histogram
+	Fingerprints []fingerprintView
+}
+
+// histogram counts the runtimes of the behaviors in the same bins, spaced
+// logarithmically between the fastest and slowest runtimes.
+type histogram struct {
+	Behaviors []string
+	Bins      []bin
+}
+
+type bin struct {
+	Low, High time.Duration
+	Bars      []bar
+}
+
+type bar struct {
+	Behavior string
+	Count    int
+	// Width relative to the largest count of the histogram, in percent.
+	Width float64
+	Color int
+}
+
+type fingerprintView struct {
+	*fingerprint
+	Control, Value string
+}
+
+// writeHTML writes the report of every experiment to opts.out, listing the
+// files on w.
+func writeHTML(w io.Writer, opts options, summaries []*summary) error {
+	if err := os.MkdirAll(opts.out, 0o755); err != nil {
+		return err
+	}
+
+	for _, s := range summaries {
+		var b bytes.Buffer
+		if err := reportTemplate.Execute(&b, newReport(s)); err != nil {
+			return err
+		}
+
+		path := filepath.Join(opts.out, fileName(s.Experiment)+".html")
+		if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, path)
+	}
+	return nil
+}
+
+// short rounds d to about three significant digits.
+func short(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	case d >= time.Microsecond:
+		return d.Round(10 * time.Nanosecond)
+	}
+	return d
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName returns the name of an experiment's report file, without its
+// extension. Names that aren't safe as is get a hash of the experiment's name,
+// so "a/b" and "a:b" don't overwrite each other or "a-b".
+func fileName(experiment string) string {
+	name := unsafeFileChars.ReplaceAllString(experiment, "-")
+	if name == "" || name[0] == '.' {
+		name = "experiment" + name
+	}
+	if name == experiment {
+		return name
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(experiment))
+	return fmt.Sprintf("%s-%08x", name, h.Sum32())
+}
+
+func newReport(s *summary) *report {
+	r := &report{summary: s, Histogram: newHistogram(s.Behaviors)}
+	for _, f := range s.Fingerprints {
+		r.Fingerprints = append(r.Fingerprints, fingerprintView{
+			fingerprint: f,
+			Control:     observed(f.Control, f.ControlErr),
+			Value:       observed(f.Value, f.Err),
+		})
+	}
+	return r
+}
+
+// observed formats a value as indented JSON, or an error.
+func observed(value json.RawMessage, err string) string {
+	if err != "" {
+		return "error: " + err
+	}
+	var b bytes.Buffer
+	if json.Indent(&b, value, "", "  ") != nil {
+		return string(value)
+	}
+	return b.String()
+}
+
+func newHistogram(behaviors []*behaviorSummary) histogram {
+	var h histogram
+	low, high := time.Duration(math.MaxInt64), time.Duration(0)
+	for _, b := range behaviors {
+		h.Behaviors = append(h.Behaviors, b.Name)
+		if len(b.runtimes) == 0 {
+			continue
+		}
+		low = min(low, b.runtimes[0])
+		high = max(high, b.runtimes[len(b.runtimes)-1])
+	}
+	if high == 0 {
+		return h
+	}
+	low = max(low, time.Microsecond)
+	high = max(high, low)
+
+	// bin i holds the runtimes up to edges[i]
+	n := histogramBins
+	if low == high {
+		n = 1
+	}
+	edges := make([]time.Duration, n)
+	for i := range edges {
+		edges[i] = time.Duration(float64(low) * math.Pow(float64(high)/float64(low), float64(i+1)/float64(n)))
+	}
+	edges[n-1] = high
+
+	h.Bins = make([]bin, n)
+	for i := range h.Bins {
+		h.Bins[i].High = edges[i]
+		if i > 0 {
+			h.Bins[i].Low = edges[i-1]
+		}
+		h.Bins[i].Bars = make([]bar, len(behaviors))
+	}
+
+	largest := 0
+	for j, b := range behaviors {
+		i := 0
+		for _, d := range b.runtimes {
+			for i < n-1 && d > edges[i] {
+				i++
+			}
+			h.Bins[i].Bars[j].Count++
+			largest = max(largest, h.Bins[i].Bars[j].Count)
+		}
+	}
+
+	for i := range h.Bins {
+		for j := range h.Bins[i].Bars {
+			bar := &h.Bins[i].Bars[j]
+			bar.Behavior = behaviors[j].Name
+			bar.Color = j % 6
+			bar.Width = math.Round(float64(bar.Count)/float64(largest)*1000) / 10
+		}
+	}
+	return h
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTML(t *testing.T) {
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	opts := options{format: "html", out: dir, bucket: time.Hour, top: 5}
+	if err := run(&stdout, &stderr, opts, []string{filepath.Join("testdata", "results.jsonl")}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := filepath.Join(dir, "count.html") + "\n" + filepath.Join(dir, "find-user.html") + "\n"
+	if stdout.String() != expected {
+		t.Errorf("expected the reports' paths, got %q", stdout.String())
+	}
+
+	html, err := os.ReadFile(filepath.Join(dir, "find-user.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, external := range []string{"<script", "<link", "src=", "url("} {
+		if bytes.Contains(html, []byte(external)) {
+			t.Errorf("expected a self-contained report, found %q", external)
+		}
+	}
+
+	golden := filepath.Join("testdata", "find-user.html")
+	if *update {
+		if err := os.WriteFile(golden, html, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if expected, err := os.ReadFile(golden); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(html, expected) {
+		t.Errorf("report differs from %s, run go test -update", golden)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	behaviors := []*behaviorSummary{
+		{Name: "control", runtimes: []time.Duration{time.Millisecond, 2 * time.Millisecond, 100 * time.Millisecond}},
+		{Name: "candidate", runtimes: []time.Duration{time.Millisecond, time.Millisecond}},
+	}
+	h := newHistogram(behaviors)
+
+	if len(h.Bins) != histogramBins || h.Bins[0].Low != 0 || h.Bins[len(h.Bins)-1].High != 100*time.Millisecond {
+		t.Fatalf("unexpected bins: %+v", h.Bins)
+	}
+	counts := make([]int, len(behaviors))
+	for _, b := range h.Bins {
+		for i, bar := range b.Bars {
+			counts[i] += bar.Count
+		}
+	}
+	if counts[0] != 3 || counts[1] != 2 {
+		t.Errorf("expected every runtime in a bin, got %v", counts)
+	}
+	if bar := h.Bins[0].Bars[1]; bar.Count != 2 || bar.Width != 100 {
+		t.Errorf("expected the first bin to be the largest, got %+v", bar)
+	}
+
+	if h := newHistogram([]*behaviorSummary{{Name: "control", runtimes: []time.Duration{time.Second}}}); len(h.Bins) != 1 {
+		t.Errorf("expected a bin for equal runtimes, got %+v", h.Bins)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"find-user":     "find-user",
+		"users/find by": "users-find-by-",
+		"../etc/passwd": "experiment..-etc-passwd-",
+		"":              "experiment-",
+	} {
+		if actual := fileName(name); !strings.HasPrefix(actual, expected) || strings.Contains(actual, "/") {
+			t.Errorf("%q: expected %q, got %q", name, expected, actual)
+		}
+	}
+
+	names := make(map[string]string)
+	for _, experiment := range []string{"a/b", "a:b", "a-b", "a b", "", "experiment"} {
+		name := fileName(experiment)
+		if other, ok := names[name]; ok {
+			t.Errorf("%q and %q share the file name %q", experiment, other, name)
+		}
+		names[name] = experiment
+	}
+	if fileName("a/b") != fileName("a/b") {
+		t.Errorf("expected file names to be stable")
+	}
+}
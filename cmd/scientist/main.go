@@ -0,0 +1,106 @@
+// Command scientist summarizes the results files written by
+// scientist.ResultFile: for every experiment, the rates of matches,
+// mismatches, ignored mismatches and errors, latency percentiles per
+// behavior, the most frequent kinds of mismatches with an example diff, and
+// the trend of mismatches over time.
+//
+//	scientist [-format text|json|csv|html] [-out dir] [-bucket 1h] [-top 5] [-experiment name] [file ...]
+//
+// It reads the standard input when no file is given, or for "-". The html
+// format writes a self-contained report per experiment to the -out
+// directory, named after the experiment, and lists the files it wrote.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/freshworks/go-scientist"
+)
+
+// maxLine is the longest record read.
+const maxLine = 16 << 20
+
+type options struct {
+	format     string
+	bucket     time.Duration
+	top        int
+	experiment string
+	out        string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.format, "format", "text", "output format: text, json, csv or html")
+	flag.DurationVar(&opts.bucket, "bucket", time.Hour, "duration of the trend's time buckets")
+	flag.IntVar(&opts.top, "top", 5, "number of mismatch fingerprints to report per experiment")
+	flag.StringVar(&opts.experiment, "experiment", "", "only report the experiment with this name")
+	flag.StringVar(&opts.out, "out", ".", "directory of the html reports")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: scientist [flags] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if opts.bucket <= 0 || opts.top < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(os.Stdout, os.Stderr, opts, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "scientist: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(stdout, stderr io.Writer, opts options, files []string) error {
+	write, ok := formats[opts.format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", opts.format)
+	}
+
+	a := newAnalysis(opts)
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, file := range files {
+		if err := read(file, stderr, a.add); err != nil {
+			return err
+		}
+	}
+	return write(stdout, opts, a.summaries())
+}
+
+// read calls fn with every record of file, warning about the lines that
+// aren't records.
+func read(file string, stderr io.Writer, fn func(rec scientist.ResultRecord)) error {
+	r := io.Reader(os.Stdin)
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLine)
+	for line := 1; scanner.Scan(); line++ {
+		var rec scientist.ResultRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			fmt.Fprintf(stderr, "scientist: %s:%d: skipped: %v\n", file, line, err)
+			continue
+		}
+		fn(rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestRun(t *testing.T) {
+	for _, format := range []string{"text", "json", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			opts := options{format: format, bucket: time.Hour, top: 5}
+			if err := run(&stdout, &stderr, opts, []string{filepath.Join("testdata", "results.jsonl")}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(stderr.String(), "results.jsonl:9: skipped") {
+				t.Errorf("expected a warning about line 9, got %q", stderr.String())
+			}
+
+			golden := filepath.Join("testdata", "summary."+format)
+			if *update {
+				if err := os.WriteFile(golden, stdout.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(stdout.Bytes(), expected) {
+				t.Errorf("output differs from %s, run go test -update:\n%s", golden, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunOptions(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	opts := options{format: "text", bucket: 24 * time.Hour, top: 1, experiment: "find-user"}
+	if err := run(&stdout, &stderr, opts, []string{filepath.Join("testdata", "results.jsonl")}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := stdout.String()
+	if strings.Contains(out, "count:") {
+		t.Errorf("expected only find-user:\n%s", out)
+	}
+	if strings.Count(out, "  candidate  1 runs") != 1 {
+		t.Errorf("expected the top fingerprint only:\n%s", out)
+	}
+	if !strings.Contains(out, "2024-05-06T00:00:00Z  7") {
+		t.Errorf("expected a daily bucket:\n%s", out)
+	}
+
+	if err := run(&stdout, &stderr, options{format: "xml"}, nil); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := newAnalysis(options{bucket: time.Hour, top: 5})
+	for _, line := range []string{
+		`{"experiment":"e","control":{"name":"control","error":"user 1 not found"},"candidates":[{"name":"candidate","value":null,"mismatched":true}]}`,
+		`{"experiment":"e","control":{"name":"control","error":"user 2 not found"},"candidates":[{"name":"candidate","value":null,"mismatched":true}]}`,
+		`{"experiment":"e","control":{"name":"control","value":{"a":[1,2]}},"candidates":[{"name":"candidate","value":{"a":[1,3]},"mismatched":true}]}`,
+		`{"experiment":"e","control":{"name":"control","value":{"a":[4,5]}},"candidates":[{"name":"candidate","value":{"a":[5,5]},"mismatched":true}]}`,
+	} {
+		if err := read(writeTemp(t, line), &bytes.Buffer{}, a.add); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fingerprints := a.summaries()[0].Fingerprints
+	if len(fingerprints) != 2 || fingerprints[0].Count != 2 || fingerprints[1].Count != 2 {
+		t.Fatalf("expected 2 fingerprints of 2 mismatches, got %+v", fingerprints)
+	}
+}
+
+func writeTemp(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// formats writes summaries in the output formats.
+var formats = map[string]func(w io.Writer, opts options, summaries []*summary) error{
+	"text": writeText,
+	"json": writeJSON,
+	"csv":  writeCSV,
+	"html": writeHTML,
+}
+
+func writeText(w io.Writer, opts options, summaries []*summary) error {
+	if len(summaries) == 0 {
+		_, err := fmt.Fprintln(w, "no results")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, s := range summaries {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "%s: %d runs from %s to %s\n", s.Experiment, s.Runs, s.First.Format(time.RFC3339), s.Last.Format(time.RFC3339))
+		fmt.Fprintf(tw, "  matched %s, mismatched %s, ignored %s, errors %s\n", s.count(s.Matched), s.count(s.Mismatched), s.count(s.Ignored), s.count(s.Errors))
+
+		fmt.Fprintf(tw, "\n  behavior\truns\terrors\ttimeouts\tpanics\tp50\tp90\tp99\tmax\n")
+		for _, b := range s.Behaviors {
+			fmt.Fprintf(tw, "  %s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n", b.Name, b.Runs, b.Errors, b.TimedOut, b.Panicked, b.P50, b.P90, b.P99, b.Max)
+		}
+		tw.Flush()
+
+		if len(s.Fingerprints) > 0 {
+			fmt.Fprintf(w, "\n  top mismatches:\n")
+			for _, f := range s.Fingerprints {
+				fmt.Fprintf(w, "  %s  %s  %d runs  %s\n", f.ID, f.Candidate, f.Count, strings.Join(f.Paths, ", "))
+				fmt.Fprintf(w, "%s", indent(f.Diff, "    "))
+			}
+		}
+
+		fmt.Fprintf(tw, "\n  trend\truns\tmismatched\tignored\terrors\n")
+		for _, b := range s.Trend {
+			fmt.Fprintf(tw, "  %s\t%d\t%s\t%d\t%d\n", b.Start.Format(time.RFC3339), b.Runs, percent(b.Mismatched, b.Runs), b.Ignored, b.Errors)
+		}
+	}
+	return tw.Flush()
+}
+
+func (s *summary) count(n int) string {
+	return fmt.Sprintf("%d (%.1f%%)", n, s.rate(n)*100)
+}
+
+func percent(n, of int) string {
+	if of == 0 {
+		return strconv.Itoa(n)
+	}
+	return fmt.Sprintf("%d (%.1f%%)", n, float64(n)/float64(of)*100)
+}
+
+func indent(s, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func writeJSON(w io.Writer, opts options, summaries []*summary) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(summaries)
+}
+
+// writeCSV writes a row per behavior of every experiment. Fingerprints and
+// trends are left out: use the JSON format for them.
+func writeCSV(w io.Writer, opts options, summaries []*summary) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"experiment", "runs", "matched", "mismatched", "ignored", "errors",
+		"behavior", "behavior_runs", "behavior_errors", "timed_out", "panicked", "behavior_mismatched",
+		"p50_ms", "p90_ms", "p99_ms", "max_ms",
+	})
+	for _, s := range summaries {
+		for _, b := range s.Behaviors {
+			cw.Write([]string{
+				s.Experiment, strconv.Itoa(s.Runs), strconv.Itoa(s.Matched), strconv.Itoa(s.Mismatched), strconv.Itoa(s.Ignored), strconv.Itoa(s.Errors),
+				b.Name, strconv.Itoa(b.Runs), strconv.Itoa(b.Errors), strconv.Itoa(b.TimedOut), strconv.Itoa(b.Panicked), strconv.Itoa(b.Mismatched),
+				millis(b.P50), millis(b.P90), millis(b.P99), millis(b.Max),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func millis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
+}
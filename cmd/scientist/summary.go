@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/freshworks/go-scientist"
+	"github.com/freshworks/go-scientist/internal/stats"
+)
+
+// summary sums up the results of an experiment.
+type summary struct {
+	Experiment string    `json:"experiment"`
+	First      time.Time `json:"first"`
+	Last       time.Time `json:"last"`
+	Runs       int       `json:"runs"`
+	Matched    int       `json:"matched"`
+	Mismatched int       `json:"mismatched"`
+	Ignored    int       `json:"ignored"`
+	// Errors counts the results where a behavior returned an error, or
+	// comparing or publishing failed.
+	Errors int `json:"errors"`
+
+	Behaviors    []*behaviorSummary `json:"behaviors"`
+	Fingerprints []*fingerprint     `json:"fingerprints"`
+	Trend        []*bucket          `json:"trend"`
+	Context      []*contextKey      `json:"context,omitempty"`
+}
+
+func (s *summary) rate(n int) float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(n) / float64(s.Runs)
+}
+
+// behaviorSummary sums up the observations of a behavior.
+type behaviorSummary struct {
+	Name       string        `json:"name"`
+	Runs       int           `json:"runs"`
+	Errors     int           `json:"errors"`
+	TimedOut   int           `json:"timed_out"`
+	Panicked   int           `json:"panicked"`
+	Mismatched int           `json:"mismatched"`
+	P50        time.Duration `json:"p50"`
+	P90        time.Duration `json:"p90"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+
+	runtimes []time.Duration
+}
+
+// fingerprint groups mismatches of a candidate that differ in the same
+// places, whatever the values.
+type fingerprint struct {
+	ID        string   `json:"id"`
+	Candidate string   `json:"candidate"`
+	Paths     []string `json:"paths"`
+	Count     int      `json:"count"`
+	// Diff of the first mismatch, with its values.
+	Diff       string          `json:"diff"`
+	Control    json.RawMessage `json:"control,omitempty"`
+	Value      json.RawMessage `json:"value,omitempty"`
+	ControlErr string          `json:"control_error,omitempty"`
+	Err        string          `json:"error,omitempty"`
+}
+
+// bucket counts the results of a period of time.
+type bucket struct {
+	Start      time.Time `json:"start"`
+	Runs       int       `json:"runs"`
+	Mismatched int       `json:"mismatched"`
+	Ignored    int       `json:"ignored"`
+	Errors     int       `json:"errors"`
+}
+
+// contextKey counts the values of a key of the results' context, the most
+// frequent first.
+type contextKey struct {
+	Key    string          `json:"key"`
+	Values []*contextValue `json:"values"`
+	// Other counts the results with values left out of Values.
+	Other int `json:"other,omitempty"`
+}
+
+type contextValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// maxContextValues bounds the values of a context key in a summary.
+const maxContextValues = 10
+
+type analysis struct {
+	opts        options
+	experiments map[string]*experimentAnalysis
+}
+
+type experimentAnalysis struct {
+	summary
+	behaviors    map[string]*behaviorSummary
+	fingerprints map[string]*fingerprint
+	buckets      map[time.Time]*bucket
+	context      map[string]map[string]int
+}
+
+func newAnalysis(opts options) *analysis {
+	return &analysis{opts: opts, experiments: make(map[string]*experimentAnalysis)}
+}
+
+func (a *analysis) add(rec scientist.ResultRecord) {
+	if a.opts.experiment != "" && rec.Experiment != a.opts.experiment {
+		return
+	}
+	rec.Time = rec.Time.UTC()
+
+	e, ok := a.experiments[rec.Experiment]
+	if !ok {
+		e = &experimentAnalysis{
+			summary:      summary{Experiment: rec.Experiment, First: rec.Time, Last: rec.Time},
+			behaviors:    make(map[string]*behaviorSummary),
+			fingerprints: make(map[string]*fingerprint),
+			buckets:      make(map[time.Time]*bucket),
+			context:      make(map[string]map[string]int),
+		}
+		a.experiments[rec.Experiment] = e
+	}
+
+	e.Runs++
+	if rec.Time.Before(e.First) {
+		e.First = rec.Time
+	}
+	if rec.Time.After(e.Last) {
+		e.Last = rec.Time
+	}
+
+	for k, v := range rec.Context {
+		if e.context[k] == nil {
+			e.context[k] = make(map[string]int)
+		}
+		e.context[k][v]++
+	}
+
+	errored := len(rec.Errors) > 0
+	e.behavior(rec.Control)
+	errored = errored || rec.Control.Error != ""
+	for _, o := range rec.Candidates {
+		e.behavior(o)
+		errored = errored || o.Error != ""
+		if o.Mismatched {
+			e.fingerprint(rec.Control, o)
+		}
+	}
+
+	start := rec.Time.Truncate(a.opts.bucket)
+	b, ok := e.buckets[start]
+	if !ok {
+		b = &bucket{Start: start}
+		e.buckets[start] = b
+	}
+	b.Runs++
+
+	switch {
+	case rec.Mismatched:
+		e.Mismatched++
+		b.Mismatched++
+	case rec.Ignored:
+		e.Ignored++
+		b.Ignored++
+	default:
+		e.Matched++
+	}
+	if errored {
+		e.Errors++
+		b.Errors++
+	}
+}
+
+func (e *experimentAnalysis) behavior(o scientist.ObservationRecord) {
+	if o.Name == "" {
+		return
+	}
+	b, ok := e.behaviors[o.Name]
+	if !ok {
+		b = &behaviorSummary{Name: o.Name}
+		e.behaviors[o.Name] = b
+	}
+
+	b.Runs++
+	b.runtimes = append(b.runtimes, o.Runtime)
+	if o.Error != "" {
+		b.Errors++
+	}
+	if o.TimedOut {
+		b.TimedOut++
+	}
+	if o.Panicked {
+		b.Panicked++
+	}
+	if o.Mismatched {
+		b.Mismatched++
+	}
+}
+
+func (e *experimentAnalysis) fingerprint(control, candidate scientist.ObservationRecord) {
+	d := diffObservations(control, candidate)
+	key := candidate.Name + "\x00" + d.key()
+	f, ok := e.fingerprints[key]
+	if !ok {
+		f = &fingerprint{
+			ID:         fingerprintID(key),
+			Candidate:  candidate.Name,
+			Paths:      d.paths(),
+			Diff:       d.String(),
+			Control:    control.Value,
+			Value:      candidate.Value,
+			ControlErr: control.Error,
+			Err:        candidate.Error,
+		}
+		e.fingerprints[key] = f
+	}
+	f.Count++
+}
+
+// summaries returns the summaries of the experiments sorted by name, with
+// the behaviors' percentiles, the top fingerprints and the trend.
+func (a *analysis) summaries() []*summary {
+	summaries := make([]*summary, 0, len(a.experiments))
+	for _, e := range a.experiments {
+		s := e.summary
+
+		for _, b := range e.behaviors {
+			sort.Slice(b.runtimes, func(i, j int) bool { return b.runtimes[i] < b.runtimes[j] })
+			b.P50 = stats.Percentile(b.runtimes, 50)
+			b.P90 = stats.Percentile(b.runtimes, 90)
+			b.P99 = stats.Percentile(b.runtimes, 99)
+			b.Max = b.runtimes[len(b.runtimes)-1]
+			s.Behaviors = append(s.Behaviors, b)
+		}
+		// the control first
+		sort.Slice(s.Behaviors, func(i, j int) bool {
+			bi, bj := s.Behaviors[i].Name, s.Behaviors[j].Name
+			if (bi == "control") != (bj == "control") {
+				return bi == "control"
+			}
+			return bi < bj
+		})
+
+		for _, f := range e.fingerprints {
+			s.Fingerprints = append(s.Fingerprints, f)
+		}
+		sort.Slice(s.Fingerprints, func(i, j int) bool {
+			fi, fj := s.Fingerprints[i], s.Fingerprints[j]
+			if fi.Count != fj.Count {
+				return fi.Count > fj.Count
+			}
+			return fi.ID < fj.ID
+		})
+		if len(s.Fingerprints) > a.opts.top {
+			s.Fingerprints = s.Fingerprints[:a.opts.top]
+		}
+
+		for _, b := range e.buckets {
+			s.Trend = append(s.Trend, b)
+		}
+		sort.Slice(s.Trend, func(i, j int) bool { return s.Trend[i].Start.Before(s.Trend[j].Start) })
+
+		for k, values := range e.context {
+			s.Context = append(s.Context, contextSummary(k, values))
+		}
+		sort.Slice(s.Context, func(i, j int) bool { return s.Context[i].Key < s.Context[j].Key })
+
+		summaries = append(summaries, &s)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Experiment < summaries[j].Experiment })
+	return summaries
+}
+
+func contextSummary(key string, values map[string]int) *contextKey {
+	c := &contextKey{Key: key}
+	for v, n := range values {
+		c.Values = append(c.Values, &contextValue{Value: v, Count: n})
+	}
+	sort.Slice(c.Values, func(i, j int) bool {
+		vi, vj := c.Values[i], c.Values[j]
+		if vi.Count != vj.Count {
+			return vi.Count > vj.Count
+		}
+		return vi.Value < vj.Value
+	})
+	if len(c.Values) > maxContextValues {
+		for _, v := range c.Values[maxContextValues:] {
+			c.Other += v.Count
+		}
+		c.Values = c.Values[:maxContextValues]
+	}
+	return c
+}
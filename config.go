@@ -0,0 +1,236 @@
+package scientist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExperimentConfig overrides an experiment at run time. The zero value leaves
+// the experiment as it is.
+type ExperimentConfig struct {
+	// Enabled turns the candidates off when false. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// Percent of the runs in which candidates run. Nil runs them every time,
+	// zero never does.
+	Percent *float64 `json:"percent,omitempty" yaml:"percent,omitempty"`
+	// Behaviors enables or disables candidates by name. Candidates that
+	// aren't listed are enabled.
+	Behaviors map[string]bool `json:"behaviors,omitempty" yaml:"behaviors,omitempty"`
+	// Timeout overrides the candidate timeout set with Experiment.Timeout.
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// PublishPercent of the results that are published. Nil publishes all of
+	// them, zero none.
+	PublishPercent *float64 `json:"publish_percent,omitempty" yaml:"publish_percent,omitempty"`
+	// Context is merged into the experiment's Context in the Result.
+	Context map[string]string `json:"context,omitempty" yaml:"context,omitempty"`
+}
+
+func (c ExperimentConfig) enabled() bool {
+	if c.Enabled != nil && !*c.Enabled {
+		return false
+	}
+	return samplePercent(c.Percent)
+}
+
+func (c ExperimentConfig) sampled() bool {
+	return samplePercent(c.PublishPercent)
+}
+
+func (c ExperimentConfig) validate() error {
+	var errs []error
+	if c.Percent != nil && (*c.Percent < 0 || *c.Percent > 100) {
+		errs = append(errs, fmt.Errorf("percent must be between 0 and 100, got %v", *c.Percent))
+	}
+	if c.PublishPercent != nil && (*c.PublishPercent < 0 || *c.PublishPercent > 100) {
+		errs = append(errs, fmt.Errorf("publish_percent must be between 0 and 100, got %v", *c.PublishPercent))
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout must not be negative, got %s", time.Duration(c.Timeout)))
+	}
+	for name := range c.Behaviors {
+		if name == "" || name == controlBehavior {
+			errs = append(errs, fmt.Errorf("behaviors can't configure %q", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// samplePercent returns true for percent (0-100) of the calls, and for all of
+// them when percent is nil.
+func samplePercent(percent *float64) bool {
+	if percent == nil {
+		return true
+	}
+	return *percent > 0 && (*percent >= 100 || rand.Float64()*100 < *percent)
+}
+
+// Duration is a time.Duration written as "250ms" in configuration files.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config is a JSON or YAML file of ExperimentConfigs keyed by experiment
+// name, reloaded when it changes:
+//
+//	widget-permissions:
+//	  percent: 10
+//	  timeout: 250ms
+//	  behaviors:
+//	    raw-sql: false
+//
+// Experiments pick up a new file on their next run. A file that fails to
+// load or validate is reported with the "config" operation and the last good
+// configuration is kept.
+type Config struct {
+	experiments atomic.Pointer[map[string]ExperimentConfig]
+	watcher     *watcher
+
+	mu       sync.Mutex
+	reporter func(...ResultError)
+}
+
+// LoadConfig loads path, failing if it's invalid, and checks it for changes
+// every interval.
+func LoadConfig(path string, interval time.Duration) (*Config, error) {
+	c := &Config{}
+	w, err := watchFile(path, interval, c.load, c.report)
+	if err != nil {
+		return nil, err
+	}
+	c.watcher = w
+	return c, nil
+}
+
+func (c *Config) load(path string, data []byte) error {
+	experiments := make(map[string]ExperimentConfig)
+	if err := unmarshalStrict(path, data, &experiments); err != nil {
+		return err
+	}
+
+	var errs configErrors
+	for name, cfg := range experiments {
+		if err := cfg.validate(); err != nil {
+			errs = append(errs, ResultError{Operation: "config", Experiment: name, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool {
+			return errs[i].Experiment < errs[j].Experiment
+		})
+		return errs
+	}
+
+	c.experiments.Store(&experiments)
+	return nil
+}
+
+// Lookup returns the configuration for an experiment.
+func (c *Config) Lookup(experiment string) (ExperimentConfig, bool) {
+	cfg, ok := (*c.experiments.Load())[experiment]
+	return cfg, ok
+}
+
+// ReportErrors sets the callback for reload errors. The default dumps them to
+// STDERR.
+func (c *Config) ReportErrors(fn func(...ResultError)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reporter = fn
+}
+
+// Err returns the error from the last reload, if it failed.
+func (c *Config) Err() error {
+	return c.watcher.Err()
+}
+
+// Close stops watching the file.
+func (c *Config) Close() error {
+	c.watcher.Close()
+	return nil
+}
+
+func (c *Config) report(err error) {
+	c.mu.Lock()
+	reporter := c.reporter
+	c.mu.Unlock()
+	reportReload(reporter, "config", err)
+}
+
+// reportReload reports an error reloading a file with operation, passing on
+// the errors of the file's entries as they are.
+func reportReload(reporter func(...ResultError), operation string, err error) {
+	var errs configErrors
+	if !errors.As(err, &errs) {
+		errs = configErrors{{Operation: operation, Err: err}}
+	}
+
+	if reporter == nil {
+		defaultErrorReporter(context.Background(), errs...)
+		return
+	}
+	reporter(errs...)
+}
+
+type configErrors []ResultError
+
+func (errs configErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = fmt.Sprintf("%s: %v", err.Experiment, err.Err)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// unmarshalStrict unmarshals a JSON or YAML file, chosen by the extension of
+// path, rejecting unknown fields.
+func unmarshalStrict(path string, data []byte, v any) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported file extension %q", ext)
+	}
+}
+
+func mergeContext(maps ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, m := range maps {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
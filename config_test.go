@@ -0,0 +1,209 @@
+package scientist
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "science.yaml")
+	writeFile(t, path, `
+widget-permissions:
+  timeout: 10ms
+  behaviors:
+    raw-sql: false
+  context:
+    owner: team-a
+`)
+
+	config, err := LoadConfig(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer config.Close()
+
+	var mu sync.Mutex
+	reported := []ResultError{}
+	config.ReportErrors(func(errs ...ResultError) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, errs...)
+	})
+
+	e := New[int]("widget-permissions")
+	e.Synchronous = true
+	e.Configure(config)
+	e.Context["user"] = "1"
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Behavior("api", func(ctx context.Context) (any, error) {
+		time.Sleep(100 * time.Millisecond)
+		return 1, nil
+	})
+	e.Behavior("raw-sql", func(ctx context.Context) (any, error) {
+		t.Errorf("did not expect disabled behavior to run")
+		return 1, nil
+	})
+
+	var result *Result[int]
+	e.Publish(func(r *Result[int]) error {
+		result = r
+		return nil
+	})
+
+	e.Run(context.Background())
+	if result == nil {
+		t.Fatalf("results never published")
+	}
+
+	assertObservationNames(t, "candidate", result.Candidates, []string{"api"})
+	if o := result.Candidates[0]; !o.TimedOut || !o.Mismatched {
+		t.Errorf("expected timed out mismatch, got %+v", o)
+	}
+
+	if result.Context["owner"] != "team-a" || result.Context["user"] != "1" {
+		t.Errorf("expected merged context, got %v", result.Context)
+	}
+
+	writeFile(t, path, "widget-permissions:\n  percent: 150\n")
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reported) > 0
+	})
+	if reported[0].Operation != "config" || reported[0].Experiment != "widget-permissions" {
+		t.Errorf("unexpected config error: %+v", reported[0])
+	}
+	if cfg, _ := config.Lookup("widget-permissions"); cfg.Timeout != Duration(10*time.Millisecond) {
+		t.Errorf("expected last good config to be kept, got %+v", cfg)
+	}
+
+	writeFile(t, path, "widget-permissions:\n  enabled: false\n")
+	eventually(t, func() bool {
+		cfg, _ := config.Lookup("widget-permissions")
+		return cfg.Enabled != nil
+	})
+
+	result = nil
+	e.Run(context.Background())
+	if result != nil {
+		t.Errorf("expected disabled experiment not to publish")
+	}
+}
+
+func TestConfigPublishPercent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "science.json")
+	writeFile(t, path, `{"sampled": {"publish_percent": 0.001}}`)
+
+	config, err := LoadConfig(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published, ran := 0, 0
+	for i := 0; i < 100; i++ {
+		e := New[int]("sampled")
+		e.Synchronous = true
+		e.Configure(config)
+		e.Use(func(ctx context.Context) (int, error) {
+			return 1, nil
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			ran++
+			return 1, nil
+		})
+		e.Publish(func(r *Result[int]) error {
+			published++
+			return nil
+		})
+		e.Run(context.Background())
+	}
+
+	if ran != 100 || published > 5 {
+		t.Errorf("expected sampled publishing, ran %d and published %d", ran, published)
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"unknown.yaml":  "widget:\n  percnt: 10\n",
+		"timeout.json":  `{"widget": {"timeout": "soon"}}`,
+		"control.yaml":  "widget:\n  behaviors:\n    control: false\n",
+		"format.toml":   "",
+		"negative.json": `{"widget": {"timeout": "-1s"}}`,
+	}
+
+	for name, content := range cases {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, content)
+		if _, err := LoadConfig(path, 0); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestConfigRampDown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "science.yaml")
+	writeFile(t, path, "widget-permissions:\n  percent: 100\n")
+
+	config, err := LoadConfig(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer config.Close()
+
+	var mu sync.Mutex
+	ran, published := 0, 0
+	e := New[int]("widget-permissions")
+	e.Synchronous = true
+	e.Configure(config)
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ran++
+		return 1, nil
+	})
+	e.Publish(func(r *Result[int]) error {
+		published++
+		return nil
+	})
+
+	e.Run(context.Background())
+	if ran != 1 || published != 1 {
+		t.Fatalf("expected the candidate to run and publish at 100%%, ran %d and published %d", ran, published)
+	}
+
+	writeFile(t, path, "widget-permissions:\n  percent: 0\n")
+	eventually(t, func() bool {
+		cfg, _ := config.Lookup("widget-permissions")
+		return cfg.Percent != nil && *cfg.Percent == 0
+	})
+
+	for i := 0; i < 100; i++ {
+		e.Run(context.Background())
+	}
+	if ran != 1 {
+		t.Errorf("expected no candidates at 0%%, got %d more runs", ran-1)
+	}
+
+	writeFile(t, path, "widget-permissions:\n  publish_percent: 0\n")
+	eventually(t, func() bool {
+		cfg, _ := config.Lookup("widget-permissions")
+		return cfg.Percent == nil && cfg.PublishPercent != nil
+	})
+
+	for i := 0; i < 100; i++ {
+		e.Run(context.Background())
+	}
+	if ran != 101 || published != 1 {
+		t.Errorf("expected candidates to run without publishing, ran %d and published %d", ran, published)
+	}
+}
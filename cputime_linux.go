@@ -0,0 +1,21 @@
+//go:build linux
+
+package scientist
+
+import (
+	"syscall"
+	"time"
+)
+
+// RUSAGE_THREAD, missing from the syscall package
+const rusageThread = 1
+
+// threadCPUTime returns the CPU time used by the calling OS thread. The
+// goroutine must be locked to its thread.
+func threadCPUTime() (time.Duration, bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(rusageThread, &ru); err != nil {
+		return 0, false
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano()), true
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package scientist
+
+import "time"
+
+// threadCPUTime isn't supported outside of Linux.
+func threadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,147 @@
+package scientist
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+)
+
+// Enabler decides whether an experiment should run its candidates.
+type Enabler func(ctx context.Context) (bool, error)
+
+// KeyFunc extracts a bucketing key (user ID, tenant ID, ...) from a context.
+type KeyFunc func(ctx context.Context) (string, bool)
+
+// Bind returns a RunIf callback that evaluates the enabler against ctx.
+func (en Enabler) Bind(ctx context.Context) func() (bool, error) {
+	return func() (bool, error) {
+		return en(ctx)
+	}
+}
+
+// ContextValue returns a KeyFunc reading ctx.Value(key).
+func ContextValue(key any) KeyFunc {
+	return func(ctx context.Context) (string, bool) {
+		switch v := ctx.Value(key).(type) {
+		case nil:
+			return "", false
+		case string:
+			return v, v != ""
+		case fmt.Stringer:
+			return v.String(), true
+		default:
+			return fmt.Sprint(v), true
+		}
+	}
+}
+
+// Percent enables a random sample of percent (0-100) of the runs.
+func Percent(percent float64) Enabler {
+	return func(ctx context.Context) (bool, error) {
+		if percent <= 0 {
+			return false, nil
+		}
+		return percent >= 100 || rand.Float64()*100 < percent, nil
+	}
+}
+
+// Sticky enables percent (0-100) of the keys returned by key. A key is
+// always in the same bucket, so raising the percentage only adds keys.
+// Runs without a key are disabled.
+//
+// Every experiment using Sticky at the same percentage picks the same keys.
+// Use StickyIn to pick different keys per experiment.
+func Sticky(key KeyFunc, percent float64) Enabler {
+	return StickyIn("", key, percent)
+}
+
+// StickyIn is Sticky with the keys bucketed within namespace, like the
+// experiment's name, so experiments in different namespaces pick
+// independent sets of keys.
+func StickyIn(namespace string, key KeyFunc, percent float64) Enabler {
+	return func(ctx context.Context) (bool, error) {
+		k, ok := key(ctx)
+		if !ok || percent <= 0 {
+			return false, nil
+		}
+		return percent >= 100 || float64(bucket(namespace, k)) < percent*100, nil
+	}
+}
+
+// Allow enables runs whose key is one of ids.
+func Allow(key KeyFunc, ids ...string) Enabler {
+	set := stringSet(ids)
+	return func(ctx context.Context) (bool, error) {
+		k, ok := key(ctx)
+		return ok && set[k], nil
+	}
+}
+
+// Deny disables runs whose key is one of ids. Runs without a key are enabled.
+func Deny(key KeyFunc, ids ...string) Enabler {
+	set := stringSet(ids)
+	return func(ctx context.Context) (bool, error) {
+		k, ok := key(ctx)
+		return !ok || !set[k], nil
+	}
+}
+
+// All enables a run when every enabler does. It stops at the first one
+// returning false or an error.
+func All(enablers ...Enabler) Enabler {
+	return func(ctx context.Context) (bool, error) {
+		for _, en := range enablers {
+			ok, err := en(ctx)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// Any enables a run when at least one enabler does. It stops at the first
+// one returning true or an error.
+func Any(enablers ...Enabler) Enabler {
+	return func(ctx context.Context) (bool, error) {
+		for _, en := range enablers {
+			ok, err := en(ctx)
+			if err != nil || ok {
+				return ok && err == nil, err
+			}
+		}
+		return false, nil
+	}
+}
+
+// Not inverts an enabler.
+func Not(en Enabler) Enabler {
+	return func(ctx context.Context) (bool, error) {
+		ok, err := en(ctx)
+		if err != nil {
+			return false, err
+		}
+		return !ok, nil
+	}
+}
+
+// bucket maps a key of a namespace onto [0, 10000) for basis point
+// precision.
+func bucket(namespace, key string) uint32 {
+	h := fnv.New32a()
+	if namespace != "" {
+		h.Write([]byte(namespace))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(key))
+	return h.Sum32() % 10000
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
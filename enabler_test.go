@@ -0,0 +1,138 @@
+package scientist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type userKey struct{}
+
+var userID = ContextValue(userKey{})
+
+func withUser(id string) context.Context {
+	return context.WithValue(context.Background(), userKey{}, id)
+}
+
+func TestPercent(t *testing.T) {
+	ctx := context.Background()
+	if ok, _ := Percent(0)(ctx); ok {
+		t.Errorf("expected 0%% to be disabled")
+	}
+	if ok, _ := Percent(100)(ctx); !ok {
+		t.Errorf("expected 100%% to be enabled")
+	}
+
+	enabled := 0
+	for i := 0; i < 10000; i++ {
+		if ok, _ := Percent(25)(ctx); ok {
+			enabled++
+		}
+	}
+	if enabled < 2000 || enabled > 3000 {
+		t.Errorf("expected roughly 2500 enabled runs, got %d", enabled)
+	}
+}
+
+func TestSticky(t *testing.T) {
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		ctx := withUser(fmt.Sprintf("user-%d", i))
+		first, _ := Sticky(userID, 30)(ctx)
+		for j := 0; j < 5; j++ {
+			if ok, _ := Sticky(userID, 30)(ctx); ok != first {
+				t.Fatalf("expected user-%d to stay in the same bucket", i)
+			}
+		}
+
+		if first {
+			enabled++
+			if ok, _ := Sticky(userID, 60)(ctx); !ok {
+				t.Errorf("expected user-%d to stay enabled when ramping up", i)
+			}
+		}
+	}
+
+	if enabled < 200 || enabled > 400 {
+		t.Errorf("expected roughly 300 enabled users, got %d", enabled)
+	}
+
+	if ok, _ := Sticky(userID, 100)(context.Background()); ok {
+		t.Errorf("expected runs without a key to be disabled")
+	}
+}
+
+func TestStickyIn(t *testing.T) {
+	both := 0
+	for i := 0; i < 1000; i++ {
+		ctx := withUser(fmt.Sprintf("user-%d", i))
+		search, _ := StickyIn("search", userID, 10)(ctx)
+		checkout, _ := StickyIn("checkout", userID, 10)(ctx)
+		if search && checkout {
+			both++
+		}
+	}
+
+	// independent 10% samples overlap on about 1% of the users
+	if both > 30 {
+		t.Errorf("expected namespaces to pick different users, %d users are in both", both)
+	}
+}
+
+func TestAllowDeny(t *testing.T) {
+	en := All(Deny(userID, "banned"), Any(Allow(userID, "staff"), Percent(0)))
+
+	cases := map[string]bool{
+		"staff":  true,
+		"banned": false,
+		"other":  false,
+	}
+	for id, expected := range cases {
+		if ok, _ := en(withUser(id)); ok != expected {
+			t.Errorf("expected %q enabled=%v, got %v", id, expected, ok)
+		}
+	}
+
+	if ok, _ := Not(Allow(userID, "staff"))(withUser("staff")); ok {
+		t.Errorf("expected Not to invert the enabler")
+	}
+}
+
+func TestEnablerErrors(t *testing.T) {
+	failing := Enabler(func(ctx context.Context) (bool, error) {
+		return true, errors.New("flags down")
+	})
+
+	for name, en := range map[string]Enabler{"all": All(failing), "any": Any(failing), "not": Not(failing)} {
+		ok, err := en(context.Background())
+		if ok || err == nil {
+			t.Errorf("%s: expected error to disable the run, got %v, %v", name, ok, err)
+		}
+	}
+}
+
+func TestEnablerRunIf(t *testing.T) {
+	e := New[int]("sticky")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	candidateRun := false
+	e.Try(func(ctx context.Context) (any, error) {
+		candidateRun = true
+		return 1, nil
+	})
+
+	ctx := withUser("staff")
+	e.RunIf(Allow(userID, "staff").Bind(ctx))
+
+	if _, err := e.Run(ctx); err != nil {
+		t.Errorf("Unexpected control error: %v", err)
+	}
+
+	if !candidateRun {
+		t.Errorf("expected candidate to run for an allowed user")
+	}
+}
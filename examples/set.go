@@ -38,6 +38,7 @@ func main() {
 
 	Run(controlFn, candidateFn)
 	RunAsyncCandidates(controlFn, candidateFn)
+	RunBenchmark(controlFn, candidateFn)
 }
 
 func Run[T any](controlFn func(ctx context.Context) (T, error), candidateFn func(ctx context.Context) (any, error)) {
@@ -93,6 +94,19 @@ func RunAsyncCandidates[T any](controlFn func(ctx context.Context) (T, error), c
 	fmt.Printf("The arbitrary example returned: %v\n", result)
 }
 
+func RunBenchmark[T any](controlFn func(ctx context.Context) (T, error), candidateFn func(ctx context.Context) (any, error)) {
+	e := scientist.New[T]("benchmark")
+	e.Use(controlFn)
+	e.Try(candidateFn)
+
+	report, err := scientist.Benchmark(context.Background(), e, scientist.BenchmarkOptions{Iterations: 1000})
+	if err != nil {
+		fmt.Printf("benchmark error: %q\n", err)
+		return
+	}
+	fmt.Print(report)
+}
+
 func publish[T any](r *scientist.Result[T]) error {
 	fmt.Println("Experiment:", r.Experiment.Name)
 	publishObservation(r.Control)
@@ -3,32 +3,58 @@ package scientist
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
 	"reflect"
 	"runtime/debug"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var ErrorOnMismatches bool
 
-func New[T any](name string) *Experiment[T] {
-	return &Experiment[T]{
+// New returns an experiment configured by opts, applied in order.
+func New[T any](name string, opts ...Option) *Experiment[T] {
+	e := &Experiment[T]{
 		Name:          name,
 		Context:       make(map[string]string),
 		behaviors:     []*behavior[any]{},
 		comparator:    defaultComparator[T],
-		runcheck:      defaultRunCheck,
+		runcheck:      defaultRunCheck[T],
 		publisher:     defaultPublisher[T],
 		errorReporter: defaultErrorReporter,
-		beforeRun:     defaultBeforeRun,
+		beforeRun:     defaultBeforeRun[T],
 		cleaner:       defaultCleaner,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 type behavior[T any] struct {
 	name string
 	fn   func(context.Context) (T, error)
+	// weight holds the float64 bits of the behavior's weight.
+	weight   atomic.Uint64
+	disabled atomic.Bool
+}
+
+func newBehavior[T any](name string, fn func(context.Context) (T, error)) *behavior[T] {
+	b := &behavior[T]{name: name, fn: fn}
+	b.setWeight(1)
+	return b
+}
+
+func (b *behavior[T]) getWeight() float64 {
+	return math.Float64frombits(b.weight.Load())
+}
+
+func (b *behavior[T]) setWeight(weight float64) {
+	b.weight.Store(math.Float64bits(weight))
 }
 
 type Experiment[T any] struct {
@@ -38,17 +64,29 @@ type Experiment[T any] struct {
 
 	control       *behavior[T]
 	behaviors     []*behavior[any]
-	ignores       []func(control T, candidate any) (bool, error)
-	comparator    func(control T, candidate any) (bool, error)
-	runcheck      func() (bool, error)
-	publisher     func(*Result[T]) error
-	errorReporter func(...ResultError)
-	beforeRun     func() error
+	ignores       []func(ctx context.Context, control T, candidate any) (bool, error)
+	comparator    func(ctx context.Context, control T, candidate any) (bool, error)
+	runcheck      func(ctx context.Context, e *Experiment[T]) (bool, error)
+	publisher     func(ctx context.Context, r *Result[T]) error
+	errorReporter func(ctx context.Context, errs ...ResultError)
+	beforeRun     func(ctx context.Context, e *Experiment[T]) error
 	cleaner       func(any) (any, error)
+	flags         FlagProvider
+	config        *Config
+	timeout       time.Duration
+	sample        int
+	breakers      *breakers
+	guardrail     *guardrail
+	onShutdown    func(Shutdown)
+	latency       *latency
+
+	measureResources bool
+	ordering         Ordering
+	capture          *Capture
 }
 
 func (e *Experiment[T]) Use(fn func(ctx context.Context) (T, error)) {
-	e.control = &behavior[T]{name: controlBehavior, fn: fn}
+	e.control = newBehavior(controlBehavior, fn)
 }
 
 func (e *Experiment[T]) Try(fn func(ctx context.Context) (any, error)) {
@@ -56,10 +94,67 @@ func (e *Experiment[T]) Try(fn func(ctx context.Context) (any, error)) {
 }
 
 func (e *Experiment[T]) Behavior(name string, fn func(ctx context.Context) (any, error)) {
-	e.behaviors = append(e.behaviors, &behavior[any]{name: name, fn: fn})
+	e.behaviors = append(e.behaviors, newBehavior(name, fn))
+}
+
+// DisableBehavior stops running a candidate until EnableBehavior is called.
+// It is safe to call while the experiment runs.
+func (e *Experiment[T]) DisableBehavior(name string) error {
+	b := e.behavior(name)
+	if b == nil {
+		return behaviorNotFound(e, name)
+	}
+	b.disabled.Store(true)
+	return nil
+}
+
+func (e *Experiment[T]) EnableBehavior(name string) error {
+	b := e.behavior(name)
+	if b == nil {
+		return behaviorNotFound(e, name)
+	}
+	b.disabled.Store(false)
+	return nil
+}
+
+// Weight sets how likely a candidate is to be picked by Sample, relative to
+// the other candidates. Candidates have a weight of 1 by default, and never
+// run with a weight of zero. It is safe to call while the experiment runs.
+func (e *Experiment[T]) Weight(name string, weight float64) error {
+	if weight < 0 {
+		return fmt.Errorf("negative weight %v for behavior %q", weight, name)
+	}
+
+	b := e.behavior(name)
+	if b == nil {
+		return behaviorNotFound(e, name)
+	}
+	b.setWeight(weight)
+	return nil
+}
+
+// Sample runs at most k candidates per run, picked according to their
+// weights. Zero runs all of them.
+func (e *Experiment[T]) Sample(k int) {
+	e.sample = k
+}
+
+func (e *Experiment[T]) behavior(name string) *behavior[any] {
+	for _, b := range e.behaviors {
+		if b.name == name {
+			return b
+		}
+	}
+	return nil
 }
 
 func (e *Experiment[T]) Compare(fn func(control T, candidate any) (bool, error)) {
+	e.CompareContext(func(ctx context.Context, control T, candidate any) (bool, error) {
+		return fn(control, candidate)
+	})
+}
+
+func (e *Experiment[T]) CompareContext(fn func(ctx context.Context, control T, candidate any) (bool, error)) {
 	e.comparator = fn
 }
 
@@ -68,34 +163,100 @@ func (e *Experiment[T]) Clean(fn func(v any) (interface{}, error)) {
 }
 
 func (e *Experiment[T]) Ignore(fn func(control T, candidate any) (bool, error)) {
+	e.IgnoreContext(func(ctx context.Context, control T, candidate any) (bool, error) {
+		return fn(control, candidate)
+	})
+}
+
+func (e *Experiment[T]) IgnoreContext(fn func(ctx context.Context, control T, candidate any) (bool, error)) {
 	e.ignores = append(e.ignores, fn)
 }
 
 func (e *Experiment[T]) RunIf(fn func() (bool, error)) {
+	e.RunIfContext(func(ctx context.Context, e *Experiment[T]) (bool, error) {
+		return fn()
+	})
+}
+
+// Timeout stops waiting for a candidate after d. Timed out observations are
+// mismatches with a context.DeadlineExceeded error.
+func (e *Experiment[T]) Timeout(d time.Duration) {
+	e.timeout = d
+}
+
+// Configure applies the entry for the experiment in c on every run.
+func (e *Experiment[T]) Configure(c *Config) {
+	e.config = c
+}
+
+// Flags consults p before running the candidates. See Flag.
+func (e *Experiment[T]) Flags(p FlagProvider) {
+	e.flags = p
+}
+
+// RunIfContext is RunIf with the context passed to Run.
+func (e *Experiment[T]) RunIfContext(fn func(ctx context.Context, e *Experiment[T]) (bool, error)) {
 	e.runcheck = fn
 }
 
+// Enable runs the candidates only when all enablers agree.
+func (e *Experiment[T]) Enable(enablers ...Enabler) {
+	en := All(enablers...)
+	e.RunIfContext(func(ctx context.Context, e *Experiment[T]) (bool, error) {
+		return en(ctx)
+	})
+}
+
 func (e *Experiment[T]) BeforeRun(fn func() error) {
+	e.BeforeRunContext(func(ctx context.Context, e *Experiment[T]) error {
+		return fn()
+	})
+}
+
+// BeforeRunContext is BeforeRun with the context passed to Run.
+func (e *Experiment[T]) BeforeRunContext(fn func(ctx context.Context, e *Experiment[T]) error) {
 	e.beforeRun = fn
 }
 
 func (e *Experiment[T]) Publish(fn func(*Result[T]) error) {
+	e.PublishContext(func(ctx context.Context, r *Result[T]) error {
+		return fn(r)
+	})
+}
+
+// PublishContext is Publish with the context passed to Run. Asynchronous
+// experiments publish after Run returns, so the context is never canceled.
+func (e *Experiment[T]) PublishContext(fn func(ctx context.Context, r *Result[T]) error) {
 	e.publisher = fn
 }
 
 func (e *Experiment[T]) ReportErrors(fn func(...ResultError)) {
+	e.ReportErrorsContext(func(ctx context.Context, errs ...ResultError) {
+		fn(errs...)
+	})
+}
+
+// ReportErrorsContext is ReportErrors with the context passed to Run.
+func (e *Experiment[T]) ReportErrorsContext(fn func(ctx context.Context, errs ...ResultError)) {
 	e.errorReporter = fn
 }
 
-func (e *Experiment[T]) isEnabled() (bool, error) {
+func (e *Experiment[T]) isEnabled(ctx context.Context) (bool, error) {
 	if e.control == nil {
 		return false, behaviorNotFound(e, controlBehavior)
 	}
 
-	return e.runcheck()
+	if currentOverrides().Enabled {
+		return true, nil
+	}
+	return e.runcheck(ctx, e)
 }
 
-func (e *Experiment[T]) Run(ctx context.Context) (T, error) {
+// Run runs the control and returns its result, running the candidates too
+// when the experiment is enabled. Run is not safe to call concurrently with
+// the experiment's setters; Build a Runner to share an experiment between
+// goroutines.
+func (e *Experiment[T]) Run(ctx context.Context, opts ...RunOption) (T, error) {
 	defer func() {
 		err := recover()
 		if err != nil {
@@ -103,61 +264,211 @@ func (e *Experiment[T]) Run(ctx context.Context) (T, error) {
 		}
 	}()
 
-	enabled, err := e.isEnabled()
+	enabled, err := e.isEnabled(ctx)
 	if err != nil {
 		return *new(T), err
 	}
 
-	control := observe(ctx, e, e.control)
-	if enabled && len(e.behaviors) > 0 {
-		r := &Result[T]{
-			Experiment: e,
-			Control:    control,
+	o := newRunOptions(opts)
+	if o.inputs != nil {
+		ctx = context.WithValue(ctx, inputsKey{}, o.inputs)
+	}
+	cfg := e.lookupConfig()
+	tags := mergeTags(mergeContext(e.Context, cfg.Context), Tags(ctx), o.tags)
+	runContext := tagStrings(tags)
+
+	var behaviors []*behavior[any]
+	var probes map[string]uint64
+	var seed int64
+	var random *rand.Rand
+	if enabled {
+		seed = e.ordering.seed(e.sample > 0)
+		if seed != 0 {
+			random = rand.New(rand.NewSource(seed))
 		}
+		behaviors, probes = e.candidates(ctx, cfg, runContext, random)
+	}
+
+	if len(behaviors) == 0 {
+		control := observe(ctx, e, e.control)
+		e.captureRun(ctx, runContext, o.inputs, control)
+		return control.Value, control.Err
+	}
 
-		if e.Synchronous {
-			e.run(ctx, r)
+	r := &Result[T]{
+		Experiment: e,
+		Context:    runContext,
+		Tags:       tags,
+		Inputs:     o.inputs,
+		Seed:       seed,
+		probes:     probes,
+	}
+
+	behaviors, before := e.ordering.arrange(behaviors, random)
+	prepared := before > 0
+	if prepared {
+		if err := e.beforeRun(ctx, e); err != nil {
+			r.addError("before_run", err)
+			behaviors = nil
 		} else {
-			go e.run(ctx, r)
+			timeout := e.candidateTimeout(cfg)
+			for _, b := range behaviors[:before] {
+				r.Candidates = append(r.Candidates, e.observeCandidate(ctx, b, timeout, r.probes[b.name]))
+			}
+			behaviors = behaviors[before:]
+		}
+	}
+
+	r.Control = observe(ctx, e, e.control)
+	e.captureRun(ctx, runContext, o.inputs, r.Control)
+	if e.Synchronous || currentOverrides().Synchronous {
+		e.run(ctx, r, behaviors, cfg, prepared)
+	} else {
+		go e.run(context.WithoutCancel(ctx), r, behaviors, cfg, prepared)
+	}
+
+	return r.Control.Value, r.Control.Err
+}
+
+// candidates returns the behaviors to run after consulting the configuration
+// and the flag provider, and sampling with random, along with the circuit
+// breaker probes of the behaviors that probe a breaker. attrs are passed to
+// the flag provider.
+func (e *Experiment[T]) candidates(ctx context.Context, cfg ExperimentConfig, attrs map[string]string, random *rand.Rand) ([]*behavior[any], map[string]uint64) {
+	forced := currentOverrides().Enabled
+	if len(e.behaviors) == 0 || (!cfg.enabled() && !forced) {
+		return nil, nil
+	}
+
+	if e.guardrail != nil && !e.guardrail.allow() {
+		return nil, nil
+	}
+
+	behaviors := make([]*behavior[any], 0, len(e.behaviors))
+	for _, b := range e.behaviors {
+		if enabled, ok := cfg.Behaviors[b.name]; (!ok || enabled) && !b.disabled.Load() && b.getWeight() > 0 {
+			behaviors = append(behaviors, b)
 		}
 	}
 
-	return control.Value, control.Err
+	if e.flags != nil && !forced {
+		flag, err := e.flags.Flag(ctx, e.Name, attrs)
+		if err != nil {
+			e.reportError(ctx, "flags", err)
+			return nil, nil
+		}
+
+		if !flag.Enabled || !samplePercent(flag.Percent) {
+			return nil, nil
+		}
+
+		if flag.Variant != "" {
+			b := e.behavior(flag.Variant)
+			if b == nil {
+				e.reportError(ctx, "flags", behaviorNotFound(e, flag.Variant))
+				return nil, nil
+			}
+			if !slices.Contains(behaviors, b) {
+				return nil, nil
+			}
+			behaviors = []*behavior[any]{b}
+		}
+	}
+
+	if e.breakers != nil {
+		behaviors = slices.DeleteFunc(behaviors, func(b *behavior[any]) bool {
+			return !e.breakers.available(b.name)
+		})
+	}
+
+	if e.sample > 0 && len(behaviors) > e.sample {
+		behaviors = weightedSample(random, behaviors, e.sample)
+	}
+
+	if e.breakers == nil {
+		return behaviors, nil
+	}
+
+	// Probes are only let through for the behaviors that will run.
+	var probes map[string]uint64
+	behaviors = slices.DeleteFunc(behaviors, func(b *behavior[any]) bool {
+		probe, ok := e.breakers.allow(b.name)
+		if probe != 0 {
+			if probes == nil {
+				probes = make(map[string]uint64)
+			}
+			probes[b.name] = probe
+		}
+		return !ok
+	})
+	return behaviors, probes
+}
+
+func (e *Experiment[T]) lookupConfig() ExperimentConfig {
+	if e.config == nil {
+		return ExperimentConfig{}
+	}
+
+	cfg, _ := e.config.Lookup(e.Name)
+	return cfg
 }
 
-func (e *Experiment[T]) run(ctx context.Context, r *Result[T]) {
+func (e *Experiment[T]) reportError(ctx context.Context, operation string, err error) {
+	e.errorReporter(ctx, ResultError{Operation: operation, Experiment: e.Name, Err: err})
+}
+
+// run observes the candidates that run after the control and publishes the
+// result. prepared is set when the before run hook was already called.
+func (e *Experiment[T]) run(ctx context.Context, r *Result[T], behaviors []*behavior[any], cfg ExperimentConfig, prepared bool) {
 	defer func() {
-		r.finalize()
+		r.finalize(ctx)
+
+		if e.guardrail != nil && len(r.Candidates) > 0 {
+			e.guardrail.record(r.IsMismatched())
+		}
 
-		if err := e.publisher(r); err != nil {
-			r.addError("publish", err)
+		if cfg.sampled() || currentOverrides().Enabled {
+			if err := e.publisher(ctx, r); err != nil {
+				r.addError("publish", err)
+			}
 		}
 
 		if len(r.Errors) > 0 {
-			e.errorReporter(r.Errors...)
+			e.errorReporter(ctx, r.Errors...)
 		}
 	}()
 
-	if err := e.beforeRun(); err != nil {
-		r.addError("before_run", err)
+	if !prepared {
+		if err := e.beforeRun(ctx, e); err != nil {
+			r.addError("before_run", err)
+			return
+		}
+	}
+
+	timeout := e.candidateTimeout(cfg)
+
+	if e.ordering.Control != ControlFirst {
+		for _, b := range behaviors {
+			r.Candidates = append(r.Candidates, e.observeCandidate(ctx, b, timeout, r.probes[b.name]))
+		}
 		return
 	}
 
-	r.Candidates = make([]*Observation[T, any], 0, len(e.behaviors))
+	r.Candidates = make([]*Observation[T, any], 0, len(behaviors))
 
 	var wg sync.WaitGroup
-	wg.Add(len(e.behaviors))
-	finished := make(chan *Observation[T, any], len(e.behaviors))
+	wg.Add(len(behaviors))
+	finished := make(chan *Observation[T, any], len(behaviors))
 	go func() {
 		wg.Wait()
 		close(finished)
 	}()
 
-	for _, b := range shuffle(e.behaviors) {
-		go func(ctx context.Context, b *behavior[any]) {
+	for _, b := range behaviors {
+		go func(b *behavior[any]) {
 			defer wg.Done()
-			finished <- observe(ctx, e, b)
-		}(context.WithoutCancel(ctx), b)
+			finished <- e.observeCandidate(ctx, b, timeout, r.probes[b.name])
+		}(b)
 	}
 
 	for candidate := range finished {
@@ -165,17 +476,73 @@ func (e *Experiment[T]) run(ctx context.Context, r *Result[T]) {
 	}
 }
 
+func (e *Experiment[T]) candidateTimeout(cfg ExperimentConfig) time.Duration {
+	if cfg.Timeout > 0 {
+		return time.Duration(cfg.Timeout)
+	}
+	return e.timeout
+}
+
+// observeCandidate observes a candidate, detached from the cancellation of
+// ctx, and records the outcome with its circuit breaker. probe is the
+// breaker probe the candidate runs as, if any.
+func (e *Experiment[T]) observeCandidate(ctx context.Context, b *behavior[any], timeout time.Duration, probe uint64) *Observation[T, any] {
+	o := observeTimeout(context.WithoutCancel(ctx), e, b, timeout)
+	if e.breakers != nil {
+		e.breakers.record(b.name, probe, o.Err != nil || o.Panicked || o.TimedOut)
+	}
+	return o
+}
+
+// observeTimeout stops waiting for a behavior after timeout and records a
+// timed out observation. The behavior's context is canceled, but it is up to
+// the behavior to return early.
+func observeTimeout[TE any, TB any](ctx context.Context, e *Experiment[TE], b *behavior[TB], timeout time.Duration) *Observation[TE, TB] {
+	if timeout <= 0 {
+		return observe(ctx, e, b)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	done := make(chan *Observation[TE, TB], 1)
+	go func() {
+		done <- observe(ctx, e, b)
+	}()
+
+	select {
+	case o := <-done:
+		return o
+	case <-ctx.Done():
+		return &Observation[TE, TB]{
+			Experiment: e,
+			Name:       b.name,
+			Started:    started,
+			Runtime:    time.Since(started),
+			Err:        fmt.Errorf("behavior %s timed out after %s: %w", b.name, timeout, ctx.Err()),
+			TimedOut:   true,
+		}
+	}
+}
+
 // https://www.calhoun.io/using-named-return-variables-to-capture-panics-in-go/
-func observe[TE any, TB any](ctx context.Context, e *Experiment[TE], b *behavior[TB]) *Observation[TE, TB] {
-	o := &Observation[TE, TB]{
+func observe[TE any, TB any](ctx context.Context, e *Experiment[TE], b *behavior[TB]) (o *Observation[TE, TB]) {
+	o = &Observation[TE, TB]{
 		Experiment: e,
 		Name:       b.name,
 		Started:    time.Now(),
 	}
 
+	if e.measureResources {
+		defer measureResources(o)()
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
+			o.Runtime = time.Since(o.Started)
 			o.Err = fmt.Errorf("recover from bad behavior %s: %v", b.name, r)
+			o.Panicked = true
 		}
 	}()
 
@@ -187,11 +554,11 @@ func observe[TE any, TB any](ctx context.Context, e *Experiment[TE], b *behavior
 	return o
 }
 
-func defaultComparator[T any](candidate T, control any) (bool, error) {
+func defaultComparator[T any](ctx context.Context, candidate T, control any) (bool, error) {
 	return reflect.DeepEqual(candidate, control), nil
 }
 
-func defaultRunCheck() (bool, error) {
+func defaultRunCheck[T any](ctx context.Context, e *Experiment[T]) (bool, error) {
 	return true, nil
 }
 
@@ -199,17 +566,17 @@ func defaultCleaner(v any) (any, error) {
 	return v, nil
 }
 
-func defaultPublisher[T any](r *Result[T]) error {
+func defaultPublisher[T any](ctx context.Context, r *Result[T]) error {
 	return nil
 }
 
-func defaultErrorReporter(errs ...ResultError) {
+func defaultErrorReporter(ctx context.Context, errs ...ResultError) {
 	for _, err := range errs {
 		fmt.Fprintf(os.Stderr, "[scientist] error during %q for %q experiment: (%T) %v\n", err.Operation, err.Experiment, err.Err, err.Err)
 	}
 }
 
-func defaultBeforeRun() error {
+func defaultBeforeRun[T any](ctx context.Context, e *Experiment[T]) error {
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -390,3 +391,185 @@ func TestExperimentWithoutWaitingForCandidates(t *testing.T) {
 		t.Errorf("results never published")
 	}
 }
+
+func TestExperimentContextHooks(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request-1")
+
+	e := New[int]("context")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		return 2, nil
+	})
+
+	seen := make(map[string]bool)
+	check := func(hook string, ctx context.Context) {
+		seen[hook] = true
+		if v := ctx.Value(key{}); v != "request-1" {
+			t.Errorf("%s: expected context value, got %v", hook, v)
+		}
+	}
+
+	e.RunIfContext(func(ctx context.Context, exp *Experiment[int]) (bool, error) {
+		check("run_if", ctx)
+		if exp != e {
+			t.Errorf("run_if: unexpected experiment %v", exp)
+		}
+		return true, nil
+	})
+	e.BeforeRunContext(func(ctx context.Context, exp *Experiment[int]) error {
+		check("before_run", ctx)
+		return nil
+	})
+	e.CompareContext(func(ctx context.Context, control int, candidate any) (bool, error) {
+		check("compare", ctx)
+		return false, nil
+	})
+	e.IgnoreContext(func(ctx context.Context, control int, candidate any) (bool, error) {
+		check("ignore", ctx)
+		return false, nil
+	})
+	e.PublishContext(func(ctx context.Context, r *Result[int]) error {
+		check("publish", ctx)
+		return errors.New("publish")
+	})
+	e.ReportErrorsContext(func(ctx context.Context, errs ...ResultError) {
+		check("report_errors", ctx)
+	})
+
+	if _, err := e.Run(ctx); err != nil {
+		t.Errorf("Unexpected control error: %v", err)
+	}
+
+	if len(seen) != 6 {
+		t.Errorf("expected all context hooks to run, got %v", seen)
+	}
+}
+
+func TestExperimentEnable(t *testing.T) {
+	type key struct{}
+	e := New[int]("enable")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	runs := 0
+	e.Try(func(ctx context.Context) (any, error) {
+		runs++
+		return 1, nil
+	})
+	e.Enable(Allow(ContextValue(key{}), "staff"))
+
+	e.Run(context.WithValue(context.Background(), key{}, "staff"))
+	e.Run(context.WithValue(context.Background(), key{}, "other"))
+
+	if runs != 1 {
+		t.Errorf("expected candidate to run once, got %d", runs)
+	}
+}
+
+func TestExperimentDisableBehavior(t *testing.T) {
+	e := New[int]("disable")
+	basicExperiment(e)
+
+	var names []string
+	e.Publish(func(r *Result[int]) error {
+		names = observationNames(r.Candidates)
+		return nil
+	})
+
+	if err := e.DisableBehavior("three"); err != nil {
+		t.Fatal(err)
+	}
+	e.Run(context.Background())
+	if fmt.Sprint(names) != "[candidate correct]" {
+		t.Errorf("expected disabled behavior to be skipped, got %v", names)
+	}
+
+	if err := e.EnableBehavior("three"); err != nil {
+		t.Fatal(err)
+	}
+	e.Run(context.Background())
+	if fmt.Sprint(names) != "[candidate correct three]" {
+		t.Errorf("expected enabled behavior to run, got %v", names)
+	}
+
+	if err := e.DisableBehavior("nope"); err == nil {
+		t.Errorf("expected an error for an unknown behavior")
+	}
+}
+
+func TestExperimentSample(t *testing.T) {
+	e := New[int]("sample")
+	basicExperiment(e)
+	e.Sample(1)
+	e.Weight("candidate", 0)
+	e.Weight("three", 3)
+
+	counts := make(map[string]int)
+	e.Publish(func(r *Result[int]) error {
+		if len(r.Candidates) != 1 {
+			t.Errorf("expected a single candidate, got %v", observationNames(r.Candidates))
+		}
+		for _, o := range r.Candidates {
+			counts[o.Name]++
+		}
+		return nil
+	})
+
+	for i := 0; i < 1000; i++ {
+		e.Run(context.Background())
+	}
+
+	if counts["candidate"] != 0 {
+		t.Errorf("expected zero weight candidate never to run, got %v", counts)
+	}
+	if counts["three"] < 650 || counts["three"] > 850 {
+		t.Errorf("expected three to run about 750 times, got %v", counts)
+	}
+
+	if err := e.Weight("three", -1); err == nil {
+		t.Errorf("expected an error for a negative weight")
+	}
+}
+
+func TestExperimentZeroWeight(t *testing.T) {
+	e := New[int]("zero-weight")
+	basicExperiment(e)
+	e.Weight("three", 0)
+
+	var names []string
+	e.Publish(func(r *Result[int]) error {
+		names = observationNames(r.Candidates)
+		return nil
+	})
+
+	e.Run(context.Background())
+	sort.Strings(names)
+	if fmt.Sprint(names) != "[candidate correct]" {
+		t.Errorf("expected the zero weight candidate not to run without sampling, got %v", names)
+	}
+}
+
+func TestExperimentWeightWhileRunning(t *testing.T) {
+	e := New[int]("weight")
+	basicExperiment(e)
+	e.Sample(1)
+	e.Publish(func(r *Result[int]) error { return nil })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			e.Weight("three", float64(i%3))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		e.Run(context.Background())
+	}
+	<-done
+}
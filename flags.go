@@ -0,0 +1,228 @@
+package scientist
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Flag is the state of an experiment in a feature flag system.
+type Flag struct {
+	// Enabled turns the candidates on.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Percent of the enabled runs in which candidates run. Nil runs them
+	// every time, zero never does, whatever the provider.
+	Percent *float64 `json:"percent,omitempty" yaml:"percent,omitempty"`
+	// Variant is the only candidate behavior to run. Empty runs all of them.
+	Variant string `json:"variant,omitempty" yaml:"variant,omitempty"`
+}
+
+// FlagProvider evaluates the flag for an experiment. attrs are the run's
+// tags as strings: the experiment's Context merged with the configuration's,
+// the tags of the context and the ones passed to Run.
+type FlagProvider interface {
+	Flag(ctx context.Context, experiment string, attrs map[string]string) (Flag, error)
+}
+
+// MemoryFlags is a FlagProvider backed by a map. Unknown experiments are
+// disabled.
+type MemoryFlags struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+func NewMemoryFlags() *MemoryFlags {
+	return &MemoryFlags{flags: make(map[string]Flag)}
+}
+
+func (m *MemoryFlags) Set(experiment string, flag Flag) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flags[experiment] = flag
+}
+
+func (m *MemoryFlags) Delete(experiment string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.flags, experiment)
+}
+
+func (m *MemoryFlags) Flag(ctx context.Context, experiment string, attrs map[string]string) (Flag, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.flags[experiment], nil
+}
+
+// EnvFlags reads flags from environment variables named Prefix followed by
+// the upper-cased experiment name, with every other character than letters
+// and digits replaced by an underscore. Prefix defaults to "SCIENTIST_".
+//
+// The value is a comma separated list of "on", "off", a percentage like
+// "25%" and "variant=<behavior>":
+//
+//	SCIENTIST_WIDGET_PERMISSIONS=10%,variant=api
+type EnvFlags struct {
+	Prefix string
+}
+
+func (f EnvFlags) Flag(ctx context.Context, experiment string, attrs map[string]string) (Flag, error) {
+	prefix := f.Prefix
+	if prefix == "" {
+		prefix = "SCIENTIST_"
+	}
+
+	name := prefix + envName(experiment)
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return Flag{}, nil
+	}
+
+	flag, err := parseEnvFlag(value)
+	if err != nil {
+		return Flag{}, fmt.Errorf("bad flag %s=%q: %w", name, value, err)
+	}
+	return flag, nil
+}
+
+func envName(experiment string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, experiment)
+}
+
+func parseEnvFlag(value string) (Flag, error) {
+	var flag Flag
+	for _, token := range strings.Split(value, ",") {
+		token = strings.TrimSpace(token)
+		switch strings.ToLower(token) {
+		case "", "off", "false", "0":
+			return Flag{}, nil
+		case "on", "true", "1":
+			flag.Enabled = true
+			continue
+		}
+
+		if variant, ok := strings.CutPrefix(token, "variant="); ok {
+			flag.Enabled = true
+			flag.Variant = variant
+			continue
+		}
+
+		percent, ok := strings.CutSuffix(token, "%")
+		if !ok {
+			return Flag{}, fmt.Errorf("unknown token %q", token)
+		}
+
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil || p < 0 || p > 100 {
+			return Flag{}, fmt.Errorf("bad percentage %q", token)
+		}
+		if p == 0 {
+			return Flag{}, nil
+		}
+		flag.Enabled = true
+		flag.Percent = &p
+	}
+	return flag, nil
+}
+
+func (f Flag) validate() error {
+	if f.Percent != nil && (*f.Percent < 0 || *f.Percent > 100) {
+		return fmt.Errorf("percent must be between 0 and 100, got %v", *f.Percent)
+	}
+	return nil
+}
+
+// FileFlags is a FlagProvider reading a JSON or YAML file mapping experiment
+// names to flags. The format is chosen by the file extension. The file is
+// reloaded when it changes; a file that fails to load or validate is
+// reported with the "flags" operation and the previous flags are kept.
+//
+//	widget-permissions:
+//	  enabled: true
+//	  percent: 10
+type FileFlags struct {
+	flags   atomic.Pointer[map[string]Flag]
+	watcher *watcher
+
+	mu       sync.Mutex
+	reporter func(...ResultError)
+}
+
+// OpenFlagFile loads path, failing if it's invalid, and checks it for changes
+// every interval.
+func OpenFlagFile(path string, interval time.Duration) (*FileFlags, error) {
+	f := &FileFlags{}
+	w, err := watchFile(path, interval, f.load, f.report)
+	if err != nil {
+		return nil, err
+	}
+	f.watcher = w
+	return f, nil
+}
+
+func (f *FileFlags) load(path string, data []byte) error {
+	flags := make(map[string]Flag)
+	if err := unmarshalStrict(path, data, &flags); err != nil {
+		return err
+	}
+
+	var errs configErrors
+	for name, flag := range flags {
+		if err := flag.validate(); err != nil {
+			errs = append(errs, ResultError{Operation: "flags", Experiment: name, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool {
+			return errs[i].Experiment < errs[j].Experiment
+		})
+		return errs
+	}
+
+	f.flags.Store(&flags)
+	return nil
+}
+
+func (f *FileFlags) Flag(ctx context.Context, experiment string, attrs map[string]string) (Flag, error) {
+	return (*f.flags.Load())[experiment], nil
+}
+
+// ReportErrors sets the callback for reload errors. The default dumps them to
+// STDERR.
+func (f *FileFlags) ReportErrors(fn func(...ResultError)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reporter = fn
+}
+
+func (f *FileFlags) report(err error) {
+	f.mu.Lock()
+	reporter := f.reporter
+	f.mu.Unlock()
+	reportReload(reporter, "flags", err)
+}
+
+// Err returns the error from the last reload, if it failed.
+func (f *FileFlags) Err() error {
+	return f.watcher.Err()
+}
+
+// Close stops watching the file.
+func (f *FileFlags) Close() error {
+	f.watcher.Close()
+	return nil
+}
@@ -0,0 +1,266 @@
+package scientist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func flagExperiment(flags FlagProvider) (*Experiment[int], map[string]int) {
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	e := New[int]("widget-permissions")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Behavior("api", func(ctx context.Context) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		runs["api"]++
+		return 1, nil
+	})
+	e.Behavior("raw-sql", func(ctx context.Context) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		runs["raw-sql"]++
+		return 1, nil
+	})
+	e.Flags(flags)
+	return e, runs
+}
+
+func TestMemoryFlags(t *testing.T) {
+	flags := NewMemoryFlags()
+	e, runs := flagExperiment(flags)
+
+	e.Run(context.Background())
+	if len(runs) != 0 {
+		t.Errorf("expected unknown experiment to be disabled, got %v", runs)
+	}
+
+	flags.Set("widget-permissions", Flag{Enabled: true})
+	e.Run(context.Background())
+	if runs["api"] != 1 || runs["raw-sql"] != 1 {
+		t.Errorf("expected all candidates to run, got %v", runs)
+	}
+
+	flags.Set("widget-permissions", Flag{Enabled: true, Variant: "api"})
+	e.Run(context.Background())
+	if runs["api"] != 2 || runs["raw-sql"] != 1 {
+		t.Errorf("expected only the variant to run, got %v", runs)
+	}
+
+	reported := []ResultError{}
+	e.ReportErrors(func(errs ...ResultError) {
+		reported = append(reported, errs...)
+	})
+	flags.Set("widget-permissions", Flag{Enabled: true, Variant: "nope"})
+	if v, err := e.Run(context.Background()); v != 1 || err != nil {
+		t.Errorf("unexpected control result: %v, %v", v, err)
+	}
+	if len(reported) != 1 || reported[0].Operation != "flags" {
+		t.Errorf("expected unknown variant to be reported, got %v", reported)
+	}
+}
+
+func TestEnvFlags(t *testing.T) {
+	flags := EnvFlags{}
+	cases := map[string]Flag{
+		"on":                   {Enabled: true},
+		"off":                  {},
+		"25%":                  {Enabled: true, Percent: percent(25)},
+		"0%":                   {},
+		"10%, variant=raw-sql": {Enabled: true, Percent: percent(10), Variant: "raw-sql"},
+	}
+
+	for value, expected := range cases {
+		t.Setenv("SCIENTIST_WIDGET_PERMISSIONS", value)
+		flag, err := flags.Flag(context.Background(), "widget-permissions", nil)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", value, err)
+		}
+		if !reflect.DeepEqual(flag, expected) {
+			t.Errorf("%q: expected %+v, got %+v", value, expected, flag)
+		}
+	}
+
+	t.Setenv("SCIENTIST_WIDGET_PERMISSIONS", "maybe")
+	if _, err := flags.Flag(context.Background(), "widget-permissions", nil); err == nil {
+		t.Errorf("expected an error for a bad value")
+	}
+}
+
+func TestFileFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFile(t, path, "widget-permissions:\n  enabled: true\n  variant: api\n")
+
+	flags, err := OpenFlagFile(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer flags.Close()
+
+	var mu sync.Mutex
+	var reported []ResultError
+	flags.ReportErrors(func(errs ...ResultError) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, errs...)
+	})
+
+	flag, _ := flags.Flag(context.Background(), "widget-permissions", nil)
+	if !flag.Enabled || flag.Variant != "api" {
+		t.Errorf("unexpected flag: %+v", flag)
+	}
+
+	writeFile(t, path, "widget-permissions: [")
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reported) == 1 && reported[0].Operation == "flags"
+	})
+	flag, _ = flags.Flag(context.Background(), "widget-permissions", nil)
+	if !flag.Enabled {
+		t.Errorf("expected the last good flags to be kept, got %+v", flag)
+	}
+
+	writeFile(t, path, "widget-permissions:\n  enabled: false\n")
+	eventually(t, func() bool {
+		flag, _ := flags.Flag(context.Background(), "widget-permissions", nil)
+		return !flag.Enabled && flags.Err() == nil
+	})
+}
+
+func TestFileFlagsJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	writeFile(t, path, `{"widget-permissions": {"enabled": true, "percent": 50}}`)
+
+	flags, err := OpenFlagFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flag, _ := flags.Flag(context.Background(), "widget-permissions", nil)
+	if !reflect.DeepEqual(flag, Flag{Enabled: true, Percent: percent(50)}) {
+		t.Errorf("unexpected flag: %+v", flag)
+	}
+
+	if _, err := OpenFlagFile(filepath.Join(t.TempDir(), "missing.json"), 0); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestFileFlagsValidation(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":  "widget-permissions:\n  enabeld: true\n",
+		"percent > 100":  "widget-permissions:\n  enabled: true\n  percent: 150\n",
+		"negative value": "widget-permissions:\n  enabled: true\n  percent: -5\n",
+	}
+
+	for name, content := range cases {
+		path := filepath.Join(t.TempDir(), "flags.yaml")
+		writeFile(t, path, content)
+		if _, err := OpenFlagFile(path, 0); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "flags.json")
+	writeFile(t, path, `{"widget-permissions": {"enabled": true}}`)
+	flags, err := OpenFlagFile(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer flags.Close()
+
+	var mu sync.Mutex
+	var reported []ResultError
+	flags.ReportErrors(func(errs ...ResultError) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, errs...)
+	})
+
+	writeFile(t, path, `{"widget-permissions": {"enabled": true, "percent": 150}}`)
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reported) > 0
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || reported[0].Operation != "flags" || reported[0].Experiment != "widget-permissions" {
+		t.Errorf("expected the invalid flag to be reported, got %v", reported)
+	}
+	if flag, _ := flags.Flag(context.Background(), "widget-permissions", nil); flag != (Flag{Enabled: true}) {
+		t.Errorf("expected the last good flags to be kept, got %+v", flag)
+	}
+}
+
+// TestFlagZeroPercent checks that every provider treats an explicit 0% as
+// off, and no percent as 100%.
+func TestFlagZeroPercent(t *testing.T) {
+	memory := NewMemoryFlags()
+	memory.Set("widget-permissions", Flag{Enabled: true, Percent: percent(0)})
+
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFile(t, path, "widget-permissions:\n  enabled: true\n  percent: 0\n")
+	file, err := OpenFlagFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SCIENTIST_WIDGET_PERMISSIONS", "on,0%")
+
+	providers := map[string]FlagProvider{"memory": memory, "file": file, "env": EnvFlags{}}
+	for name, provider := range providers {
+		e, runs := flagExperiment(provider)
+		for i := 0; i < 100; i++ {
+			e.Run(context.Background())
+		}
+		if len(runs) != 0 {
+			t.Errorf("%s: expected no candidates at 0%%, got %v", name, runs)
+		}
+	}
+
+	memory.Set("widget-permissions", Flag{Enabled: true})
+	e, runs := flagExperiment(memory)
+	for i := 0; i < 100; i++ {
+		e.Run(context.Background())
+	}
+	if runs["api"] != 100 {
+		t.Errorf("expected candidates on every run without a percent, got %v", runs)
+	}
+}
+
+func percent(p float64) *float64 {
+	return &p
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func eventually(t *testing.T, fn func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !fn() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition never met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -1,3 +1,11 @@
 module github.com/freshworks/go-scientist
 
-go 1.21
+go 1.22.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/tools v0.30.0
+)
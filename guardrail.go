@@ -0,0 +1,140 @@
+package scientist
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GuardrailConfig configures when an experiment shuts itself down. A run
+// counts as a mismatch when any candidate mismatched; ignored mismatches
+// don't count.
+type GuardrailConfig struct {
+	// MismatchRate (0-1) of the runs in Window above which the experiment
+	// shuts down.
+	MismatchRate float64
+	// MinSamples in Window before MismatchRate applies. Defaults to 100.
+	MinSamples int
+	// Window of time runs are remembered for. Defaults to five minutes.
+	Window time.Duration
+}
+
+// Shutdown records why a guardrail stopped an experiment.
+type Shutdown struct {
+	Experiment   string
+	Reason       string
+	MismatchRate float64
+	Samples      int
+	Time         time.Time
+}
+
+// Guardrail stops running the candidates once the experiment mismatches too
+// often. The experiment stays shut down until Restart is called.
+func (e *Experiment[T]) Guardrail(cfg GuardrailConfig) {
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 100
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 5 * time.Minute
+	}
+
+	onShutdown := e.onShutdown
+	if onShutdown == nil {
+		onShutdown = func(Shutdown) {}
+	}
+	e.guardrail = &guardrail{experiment: e.Name, cfg: cfg, onShutdown: onShutdown}
+}
+
+// OnShutdown sets a callback for when the guardrail shuts the experiment
+// down. It is called synchronously from the run that tripped it. It doesn't
+// set up a guardrail by itself.
+func (e *Experiment[T]) OnShutdown(fn func(Shutdown)) {
+	e.onShutdown = fn
+	if e.guardrail != nil {
+		e.guardrail.onShutdown = fn
+	}
+}
+
+// Shutdown returns why the experiment was shut down by its guardrail.
+func (e *Experiment[T]) Shutdown() (Shutdown, bool) {
+	if e.guardrail == nil {
+		return Shutdown{}, false
+	}
+	return e.guardrail.shutdown()
+}
+
+// Restart runs the candidates of an experiment that was shut down again.
+func (e *Experiment[T]) Restart() {
+	if e.guardrail != nil {
+		e.guardrail.restart()
+	}
+}
+
+type guardrail struct {
+	experiment string
+	cfg        GuardrailConfig
+	onShutdown func(Shutdown)
+	now        func() time.Time
+
+	mu      sync.Mutex
+	window  window
+	stopped *Shutdown
+}
+
+func (g *guardrail) clock() time.Time {
+	if g.now != nil {
+		return g.now()
+	}
+	return time.Now()
+}
+
+func (g *guardrail) allow() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stopped == nil
+}
+
+func (g *guardrail) record(mismatched bool) {
+	g.mu.Lock()
+	if g.stopped != nil {
+		g.mu.Unlock()
+		return
+	}
+
+	now := g.clock()
+	mismatches, total := g.window.add(now, g.cfg.Window, mismatched)
+	rate := float64(mismatches) / float64(total)
+	if total < g.cfg.MinSamples || rate <= g.cfg.MismatchRate {
+		g.mu.Unlock()
+		return
+	}
+
+	g.stopped = &Shutdown{
+		Experiment:   g.experiment,
+		Reason:       fmt.Sprintf("mismatch rate %.2f over %d runs exceeded %.2f", rate, total, g.cfg.MismatchRate),
+		MismatchRate: rate,
+		Samples:      total,
+		Time:         now,
+	}
+	g.window.reset()
+	shutdown := *g.stopped
+	g.mu.Unlock()
+
+	g.onShutdown(shutdown)
+}
+
+func (g *guardrail) shutdown() (Shutdown, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped == nil {
+		return Shutdown{}, false
+	}
+	return *g.stopped, true
+}
+
+func (g *guardrail) restart() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stopped = nil
+	g.window.reset()
+}
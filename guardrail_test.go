@@ -0,0 +1,115 @@
+package scientist
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuardrail(t *testing.T) {
+	e := New[int]("guardrail")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	calls := 0
+	e.Try(func(ctx context.Context) (any, error) {
+		calls++
+		if calls%2 == 0 {
+			return 2, nil
+		}
+		return 1, nil
+	})
+
+	e.Guardrail(GuardrailConfig{MismatchRate: 0.25, MinSamples: 10, Window: time.Minute})
+
+	var shutdowns []Shutdown
+	e.OnShutdown(func(s Shutdown) {
+		shutdowns = append(shutdowns, s)
+	})
+
+	for i := 0; i < 20; i++ {
+		e.Run(context.Background())
+	}
+
+	if calls != 10 {
+		t.Errorf("expected the experiment to shut down after 10 runs, got %d", calls)
+	}
+
+	s, ok := e.Shutdown()
+	if !ok {
+		t.Fatalf("expected the experiment to be shut down")
+	}
+	if len(shutdowns) != 1 || shutdowns[0] != s {
+		t.Errorf("expected a single shutdown callback, got %v", shutdowns)
+	}
+	if s.Samples != 10 || s.MismatchRate != 0.5 || !strings.Contains(s.Reason, "mismatch rate 0.50") {
+		t.Errorf("unexpected shutdown: %+v", s)
+	}
+
+	if v, err := e.Run(context.Background()); v != 1 || err != nil {
+		t.Errorf("expected the control to keep running, got %v, %v", v, err)
+	}
+
+	e.Restart()
+	e.Run(context.Background())
+	if calls != 11 {
+		t.Errorf("expected the candidate to run after a restart, got %d", calls)
+	}
+	if _, ok := e.Shutdown(); ok {
+		t.Errorf("expected the experiment to be restarted")
+	}
+}
+
+func TestGuardrailIgnoresIgnoredMismatches(t *testing.T) {
+	e := New[int]("guardrail")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		return 2, nil
+	})
+	e.Ignore(func(control int, candidate any) (bool, error) {
+		return true, nil
+	})
+	e.Guardrail(GuardrailConfig{MinSamples: 5})
+
+	for i := 0; i < 10; i++ {
+		e.Run(context.Background())
+	}
+
+	if s, ok := e.Shutdown(); ok {
+		t.Errorf("did not expect ignored mismatches to shut down the experiment: %+v", s)
+	}
+}
+
+func TestOnShutdownWithoutGuardrail(t *testing.T) {
+	e := New[int]("guardrail")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		return 2, nil
+	})
+
+	shutdowns := 0
+	e.OnShutdown(func(Shutdown) { shutdowns++ })
+	for i := 0; i < 200; i++ {
+		e.Run(context.Background())
+	}
+	if s, ok := e.Shutdown(); ok || shutdowns != 0 {
+		t.Fatalf("did not expect a callback alone to shut the experiment down: %+v", s)
+	}
+
+	e.Guardrail(GuardrailConfig{MismatchRate: 0.5, MinSamples: 5})
+	for i := 0; i < 5; i++ {
+		e.Run(context.Background())
+	}
+	if shutdowns != 1 {
+		t.Errorf("expected the guardrail to keep the callback, got %d shutdowns", shutdowns)
+	}
+}
@@ -2,7 +2,9 @@ package scientist
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 	"time"
 )
 
@@ -19,14 +21,110 @@ func Bool(ok interface{}, err error) (bool, error) {
 	}
 }
 
-// Shuffle randomizes the behavior access.
-func shuffle[T any](behaviors []T) []T {
-	r := rand.New(rand.NewSource(int64(time.Now().Nanosecond())))
+// shuffle returns a copy of items in a uniformly random order
+// (Fisher-Yates).
+func shuffle[T any](r *rand.Rand, items []T) []T {
+	shuffled := append([]T(nil), items...)
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+	return shuffled
+}
+
+// weightedSample picks k behaviors without replacement with r, each with a
+// probability proportional to its weight (Efraimidis-Spirakis). Behaviors with
+// a weight of zero are never picked.
+func weightedSample[T any](r *rand.Rand, behaviors []*behavior[T], k int) []*behavior[T] {
+	type keyed struct {
+		key float64
+		b   *behavior[T]
+	}
+
+	keys := make([]keyed, 0, len(behaviors))
+	for _, b := range behaviors {
+		if weight := b.getWeight(); weight > 0 {
+			keys = append(keys, keyed{key: math.Pow(r.Float64(), 1/weight), b: b})
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].key > keys[j].key
+	})
+
+	if len(keys) > k {
+		keys = keys[:k]
+	}
+
+	sampled := make([]*behavior[T], len(keys))
+	for i, kb := range keys {
+		sampled[i] = kb.b
+	}
+	return sampled
+}
+
+// windowBuckets is the number of buckets a window's span is divided into.
+const windowBuckets = 60
+
+// window counts pass/fail outcomes in fixed time buckets, so that it takes
+// the same memory whatever the traffic. Outcomes are forgotten a bucket at a
+// time, once the bucket is older than the window's span.
+type window struct {
+	buckets  []windowBucket
+	failures int
+	total    int
 
-	arr := behaviors
-	for i := len(arr) - 1; i > 0; i-- {
-		j := r.Intn(i)
-		arr[i], arr[j] = arr[j], arr[i]
+	// streak is the number of consecutive failures, last at the time of the
+	// last outcome.
+	streak int
+	last   time.Time
+}
+
+type windowBucket struct {
+	start    time.Time
+	failures int
+	total    int
+}
+
+// add records an outcome, forgets the ones older than span and returns the
+// number of failures and outcomes left.
+func (w *window) add(now time.Time, span time.Duration, failed bool) (int, int) {
+	width := max(span/windowBuckets, 1)
+	cutoff := now.Add(-span)
+	i := 0
+	for ; i < len(w.buckets) && !w.buckets[i].start.After(cutoff); i++ {
+		w.failures -= w.buckets[i].failures
+		w.total -= w.buckets[i].total
+	}
+	w.buckets = w.buckets[i:]
+
+	start := now.Truncate(width)
+	if n := len(w.buckets); n == 0 || w.buckets[n-1].start.Before(start) {
+		w.buckets = append(w.buckets, windowBucket{start: start})
+	}
+	b := &w.buckets[len(w.buckets)-1]
+	b.total++
+	w.total++
+	if failed {
+		b.failures++
+		w.failures++
 	}
-	return arr
+
+	if !failed || now.Sub(w.last) > span {
+		w.streak = 0
+	}
+	if failed {
+		w.streak++
+	}
+	w.last = now
+	return w.failures, w.total
+}
+
+// trailing returns the number of consecutive failures at the end.
+func (w *window) trailing() int {
+	return w.streak
+}
+
+func (w *window) reset() {
+	*w = window{}
 }
@@ -0,0 +1,18 @@
+// Package stats holds the statistics shared by scientist and its commands.
+package stats
+
+import (
+	"math"
+	"time"
+)
+
+// Percentile returns the nearest-rank percentile p (0-100) of sorted values:
+// the smallest value that at least p percent of them are lower than or equal
+// to.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
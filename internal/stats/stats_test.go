@@ -0,0 +1,19 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4}
+	cases := map[float64]time.Duration{0: 1, 25: 1, 26: 2, 50: 2, 51: 3, 75: 3, 99: 4, 100: 4}
+	for p, expected := range cases {
+		if actual := Percentile(sorted, p); actual != expected {
+			t.Errorf("p%v: expected %d, got %d", p, expected, actual)
+		}
+	}
+	if Percentile(nil, 50) != 0 {
+		t.Errorf("expected 0 without values")
+	}
+}
@@ -0,0 +1,95 @@
+package scientist
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/freshworks/go-scientist/internal/stats"
+)
+
+// LatencyBudget marks candidates that are slower than allowed as slow. Slow
+// observations are tracked separately from mismatches. Every limit that is
+// set applies; zero values are ignored.
+type LatencyBudget struct {
+	// Max runtime of a candidate.
+	Max time.Duration
+	// Ratio of a candidate's runtime to the control's, like 1.5 for 50%
+	// slower.
+	Ratio float64
+	// Delta over the control's runtime.
+	Delta time.Duration
+	// Percentile (0-100) of the control's runtimes over the last Window runs
+	// that a candidate may not exceed, like 99.
+	Percentile float64
+	// Window of control runtimes kept for Percentile. Defaults to 1000.
+	Window int
+}
+
+// Latency sets a latency budget for the candidates.
+func (e *Experiment[T]) Latency(budget LatencyBudget) {
+	if budget.Window <= 0 {
+		budget.Window = 1000
+	}
+	e.latency = &latency{budget: budget}
+}
+
+type latency struct {
+	budget LatencyBudget
+
+	mu       sync.Mutex
+	runtimes []time.Duration
+	next     int
+	// sorted holds runtimes in order, updated as they come and go.
+	sorted []time.Duration
+}
+
+// slow reports whether a candidate ran over budget.
+func (l *latency) slow(control, candidate time.Duration) bool {
+	b := l.budget
+	switch {
+	case b.Max > 0 && candidate > b.Max:
+		return true
+	case b.Ratio > 0 && float64(candidate) > b.Ratio*float64(control):
+		return true
+	case b.Delta > 0 && candidate > control+b.Delta:
+		return true
+	case b.Percentile > 0:
+		p, ok := l.percentile()
+		return ok && candidate > p
+	}
+	return false
+}
+
+// record adds a control runtime to the window.
+func (l *latency) record(control time.Duration) {
+	if l.budget.Percentile <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.runtimes) < l.budget.Window {
+		l.runtimes = append(l.runtimes, control)
+	} else {
+		evicted := l.runtimes[l.next]
+		l.runtimes[l.next] = control
+		l.next = (l.next + 1) % len(l.runtimes)
+
+		i, _ := slices.BinarySearch(l.sorted, evicted)
+		l.sorted = slices.Delete(l.sorted, i, i+1)
+	}
+
+	i, _ := slices.BinarySearch(l.sorted, control)
+	l.sorted = slices.Insert(l.sorted, i, control)
+}
+
+func (l *latency) percentile() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.sorted) == 0 {
+		return 0, false
+	}
+	return stats.Percentile(l.sorted, l.budget.Percentile), true
+}
@@ -0,0 +1,92 @@
+package scientist
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLatencyBudget(t *testing.T) {
+	cases := []struct {
+		budget    LatencyBudget
+		control   time.Duration
+		candidate time.Duration
+		slow      bool
+	}{
+		{LatencyBudget{Max: 10 * time.Millisecond}, time.Millisecond, 11 * time.Millisecond, true},
+		{LatencyBudget{Max: 10 * time.Millisecond}, time.Millisecond, 10 * time.Millisecond, false},
+		{LatencyBudget{Ratio: 1.5}, 10 * time.Millisecond, 16 * time.Millisecond, true},
+		{LatencyBudget{Ratio: 1.5}, 10 * time.Millisecond, 14 * time.Millisecond, false},
+		{LatencyBudget{Delta: time.Millisecond}, 10 * time.Millisecond, 12 * time.Millisecond, true},
+		{LatencyBudget{Delta: time.Millisecond}, 10 * time.Millisecond, 11 * time.Millisecond, false},
+		{LatencyBudget{Max: time.Second, Ratio: 2}, 10 * time.Millisecond, 30 * time.Millisecond, true},
+	}
+
+	for i, c := range cases {
+		l := &latency{budget: c.budget}
+		if slow := l.slow(c.control, c.candidate); slow != c.slow {
+			t.Errorf("case %d: expected slow=%v, got %v", i, c.slow, slow)
+		}
+	}
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	l := &latency{budget: LatencyBudget{Percentile: 90, Window: 10}}
+	if l.slow(0, time.Hour) {
+		t.Errorf("expected no budget without control runtimes")
+	}
+
+	for i := 1; i <= 20; i++ {
+		l.record(time.Duration(i) * time.Millisecond)
+	}
+
+	// the window holds 11-20ms, so p90 is 19ms
+	if !l.slow(0, 20*time.Millisecond) || l.slow(0, 19*time.Millisecond) {
+		t.Errorf("expected the p90 of the last 10 control runtimes to be 19ms")
+	}
+}
+
+func TestExperimentSlow(t *testing.T) {
+	e := New[int]("slow")
+	e.Synchronous = true
+	e.Latency(LatencyBudget{Max: 5 * time.Millisecond})
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Behavior("slow", func(ctx context.Context) (any, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 1, nil
+	})
+	e.Behavior("fast", func(ctx context.Context) (any, error) {
+		return 2, nil
+	})
+
+	metrics := NewMetrics()
+	var result *Result[int]
+	e.Publish(func(r *Result[int]) error {
+		result = r
+		RecordMetrics(metrics, r)
+		return nil
+	})
+	e.Run(context.Background())
+
+	assertObservationNames(t, "slow", result.Slow, []string{"slow"})
+	assertObservationNames(t, "mismatched", result.Mismatched, []string{"fast"})
+	if !result.IsSlow() || !result.IsMismatched() {
+		t.Errorf("expected a slow and mismatched result")
+	}
+
+	m := metrics.Snapshot()["slow"]
+	if m.Runs != 1 || m.Slow != 1 || m.Mismatched != 1 {
+		t.Errorf("unexpected experiment metrics: %+v", m)
+	}
+	if b := m.Behaviors["slow"]; b.Slow != 1 || b.Mismatched != 0 || b.Runtime.Min < 10*time.Millisecond {
+		t.Errorf("unexpected slow behavior metrics: %+v", b)
+	}
+	if b := m.Behaviors["fast"]; b.Slow != 0 || b.Mismatched != 1 {
+		t.Errorf("unexpected fast behavior metrics: %+v", b)
+	}
+	if b := m.Behaviors["control"]; b.Runs != 1 {
+		t.Errorf("unexpected control metrics: %+v", b)
+	}
+}
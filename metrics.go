@@ -0,0 +1,250 @@
+package scientist
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/freshworks/go-scientist/internal/stats"
+)
+
+// Metrics aggregates published results in memory, for exporting to a
+// metrics system or inspecting in tests.
+type Metrics struct {
+	mu          sync.Mutex
+	experiments map[string]*ExperimentMetrics
+}
+
+// ExperimentMetrics counts the results of an experiment. A result is
+// mismatched, ignored or slow if any of its candidates is.
+type ExperimentMetrics struct {
+	Runs       int64
+	Matched    int64
+	Mismatched int64
+	Ignored    int64
+	Slow       int64
+	Errors     int64
+	Behaviors  map[string]*BehaviorMetrics
+}
+
+// BehaviorMetrics counts the observations of a behavior, including the
+// control.
+type BehaviorMetrics struct {
+	Runs       int64
+	Errors     int64
+	Panics     int64
+	Timeouts   int64
+	Mismatched int64
+	Ignored    int64
+	Slow       int64
+	Runtime    RuntimeStats
+
+	// Resources of the observations measured with MeasureResources.
+	Measured     int64
+	CPUTime      RuntimeStats
+	AllocBytes   uint64
+	AllocObjects uint64
+}
+
+// BytesPerRun is the mean of the bytes allocated by measured observations.
+func (bm BehaviorMetrics) BytesPerRun() float64 {
+	if bm.Measured == 0 {
+		return 0
+	}
+	return float64(bm.AllocBytes) / float64(bm.Measured)
+}
+
+// ObjectsPerRun is the mean of the objects allocated by measured
+// observations.
+func (bm BehaviorMetrics) ObjectsPerRun() float64 {
+	if bm.Measured == 0 {
+		return 0
+	}
+	return float64(bm.AllocObjects) / float64(bm.Measured)
+}
+
+// RuntimeStats summarizes runtimes. Percentiles are computed over the most
+// recent runs.
+type RuntimeStats struct {
+	Count  int64
+	Total  time.Duration
+	Min    time.Duration
+	Max    time.Duration
+	recent []time.Duration
+	next   int
+}
+
+const recentRuntimes = 1000
+
+func NewMetrics() *Metrics {
+	return &Metrics{experiments: make(map[string]*ExperimentMetrics)}
+}
+
+// MetricsPublisher returns a Publish callback recording results in m.
+func MetricsPublisher[T any](m *Metrics) func(*Result[T]) error {
+	return func(r *Result[T]) error {
+		RecordMetrics(m, r)
+		return nil
+	}
+}
+
+// RecordMetrics records a result in m.
+func RecordMetrics[T any](m *Metrics, r *Result[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	em, ok := m.experiments[r.Experiment.Name]
+	if !ok {
+		em = &ExperimentMetrics{Behaviors: make(map[string]*BehaviorMetrics)}
+		m.experiments[r.Experiment.Name] = em
+	}
+
+	em.Runs++
+	em.Errors += int64(len(r.Errors))
+	switch {
+	case r.IsMismatched():
+		em.Mismatched++
+	case r.IsIgnored():
+		em.Ignored++
+	default:
+		em.Matched++
+	}
+	if r.IsSlow() {
+		em.Slow++
+	}
+
+	if r.Control != nil {
+		em.behavior(r.Control.Name).add(observedFrom(r.Control))
+	}
+	for _, o := range r.Candidates {
+		em.behavior(o.Name).add(observedFrom(o))
+	}
+}
+
+// observed is the part of an Observation that metrics care about.
+type observed struct {
+	runtime    time.Duration
+	err        bool
+	panicked   bool
+	timedOut   bool
+	mismatched bool
+	ignored    bool
+	slow       bool
+
+	measured     bool
+	cpuTime      time.Duration
+	allocBytes   uint64
+	allocObjects uint64
+}
+
+func observedFrom[TE any, TVal any](o *Observation[TE, TVal]) observed {
+	return observed{
+		runtime:    o.Runtime,
+		err:        o.Err != nil,
+		panicked:   o.Panicked,
+		timedOut:   o.TimedOut,
+		mismatched: o.Mismatched,
+		ignored:    o.Ignored,
+		slow:       o.Slow,
+
+		measured:     o.ResourcesMeasured,
+		cpuTime:      o.CPUTime,
+		allocBytes:   o.allocBytes,
+		allocObjects: o.allocObjects,
+	}
+}
+
+// Snapshot returns a copy of the metrics, keyed by experiment name.
+func (m *Metrics) Snapshot() map[string]ExperimentMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]ExperimentMetrics, len(m.experiments))
+	for name, em := range m.experiments {
+		copied := *em
+		copied.Behaviors = make(map[string]*BehaviorMetrics, len(em.Behaviors))
+		for bname, bm := range em.Behaviors {
+			b := *bm
+			b.Runtime.recent = append([]time.Duration(nil), bm.Runtime.recent...)
+			b.CPUTime.recent = append([]time.Duration(nil), bm.CPUTime.recent...)
+			copied.Behaviors[bname] = &b
+		}
+		snapshot[name] = copied
+	}
+	return snapshot
+}
+
+// Reset forgets all recorded results.
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.experiments = make(map[string]*ExperimentMetrics)
+}
+
+func (em *ExperimentMetrics) behavior(name string) *BehaviorMetrics {
+	bm, ok := em.Behaviors[name]
+	if !ok {
+		bm = &BehaviorMetrics{}
+		em.Behaviors[name] = bm
+	}
+	return bm
+}
+
+func (bm *BehaviorMetrics) add(o observed) {
+	bm.Runs++
+	bm.Runtime.add(o.runtime)
+	for _, c := range []struct {
+		ok    bool
+		count *int64
+	}{
+		{o.err, &bm.Errors},
+		{o.panicked, &bm.Panics},
+		{o.timedOut, &bm.Timeouts},
+		{o.mismatched, &bm.Mismatched},
+		{o.ignored, &bm.Ignored},
+		{o.slow, &bm.Slow},
+	} {
+		if c.ok {
+			*c.count++
+		}
+	}
+
+	if o.measured {
+		bm.Measured++
+		bm.CPUTime.add(o.cpuTime)
+		bm.AllocBytes += o.allocBytes
+		bm.AllocObjects += o.allocObjects
+	}
+}
+
+func (s *RuntimeStats) add(d time.Duration) {
+	if s.Count == 0 || d < s.Min {
+		s.Min = d
+	}
+	if d > s.Max {
+		s.Max = d
+	}
+	s.Count++
+	s.Total += d
+
+	if len(s.recent) < recentRuntimes {
+		s.recent = append(s.recent, d)
+		return
+	}
+	s.recent[s.next] = d
+	s.next = (s.next + 1) % recentRuntimes
+}
+
+func (s RuntimeStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Percentile returns the percentile p (0-100) of the recent runtimes.
+func (s RuntimeStats) Percentile(p float64) time.Duration {
+	sorted := append([]time.Duration(nil), s.recent...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return stats.Percentile(sorted, p)
+}
@@ -0,0 +1,60 @@
+package scientist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+
+	for i := 0; i < 10; i++ {
+		e := New[int]("metrics")
+		e.Synchronous = true
+		e.Use(func(ctx context.Context) (int, error) {
+			return 1, nil
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			if i%2 == 0 {
+				return 0, errors.New("nope")
+			}
+			return 1, nil
+		})
+		e.Publish(MetricsPublisher[int](metrics))
+		e.Run(context.Background())
+	}
+
+	m := metrics.Snapshot()["metrics"]
+	if m.Runs != 10 || m.Matched != 5 || m.Mismatched != 5 {
+		t.Errorf("unexpected experiment metrics: %+v", m)
+	}
+
+	candidate := m.Behaviors["candidate"]
+	if candidate.Runs != 10 || candidate.Errors != 5 || candidate.Mismatched != 5 {
+		t.Errorf("unexpected candidate metrics: %+v", candidate)
+	}
+
+	metrics.Reset()
+	if len(metrics.Snapshot()) != 0 {
+		t.Errorf("expected metrics to be reset")
+	}
+}
+
+func TestRuntimeStats(t *testing.T) {
+	var s RuntimeStats
+	for i := 1; i <= 100; i++ {
+		s.add(time.Duration(i) * time.Millisecond)
+	}
+
+	if s.Min != time.Millisecond || s.Max != 100*time.Millisecond || s.Count != 100 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	if mean := s.Mean(); mean != 50500*time.Microsecond {
+		t.Errorf("unexpected mean: %s", mean)
+	}
+	if p := s.Percentile(95); p != 95*time.Millisecond {
+		t.Errorf("unexpected p95: %s", p)
+	}
+}
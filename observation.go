@@ -9,8 +9,20 @@ type Observation[TE any, TVal any] struct {
 	Runtime    time.Duration
 	Value      TVal
 	Err        error
+	TimedOut   bool
+	Panicked   bool
 	Mismatched bool
 	Ignored    bool
+	Slow       bool
+
+	// Set when the experiment measures resources. See MeasureResources.
+	ResourcesMeasured bool
+	CPUTime           time.Duration
+
+	// allocBytes and allocObjects are read from process wide counters, so
+	// they only mean something in aggregate, in Metrics.
+	allocBytes   uint64
+	allocObjects uint64
 }
 
 func (o *Observation[TE, TVal]) CleanedValue() (interface{}, error) {
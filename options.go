@@ -0,0 +1,215 @@
+package scientist
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Option configures an experiment in New. Options that don't depend on the
+// experiment's type apply to experiments of any type, so they can be bundled
+// with Options and shared.
+type Option func(configurable)
+
+// configurable is the part of an experiment that doesn't depend on its type.
+type configurable interface {
+	Behavior(name string, fn func(ctx context.Context) (any, error))
+	Clean(fn func(v any) (interface{}, error))
+	RunIf(fn func() (bool, error))
+	Enable(enablers ...Enabler)
+	BeforeRun(fn func() error)
+	ReportErrors(fn func(...ResultError))
+	ReportErrorsContext(fn func(ctx context.Context, errs ...ResultError))
+	Weight(name string, weight float64) error
+	DisableBehavior(name string) error
+	Timeout(d time.Duration)
+	Configure(c *Config)
+	Flags(p FlagProvider)
+	Sample(k int)
+	Order(o Ordering)
+	Breaker(cfg BreakerConfig)
+	OnBreakerChange(fn func(BreakerEvent))
+	Guardrail(cfg GuardrailConfig)
+	OnShutdown(fn func(Shutdown))
+	Latency(budget LatencyBudget)
+	MeasureResources(enabled bool)
+	Capture(c *Capture)
+
+	setSynchronous(synchronous bool)
+	setContext(context map[string]string)
+}
+
+func (e *Experiment[T]) setSynchronous(synchronous bool) {
+	e.Synchronous = synchronous
+}
+
+func (e *Experiment[T]) setContext(context map[string]string) {
+	for k, v := range context {
+		e.Context[k] = v
+	}
+}
+
+// Options bundles options into one, applied in order.
+func Options(opts ...Option) Option {
+	return func(c configurable) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
+
+// WithSetup calls fn with the experiment, for anything without an option.
+// It panics when applied to an experiment of another type.
+func WithSetup[T any](fn func(e *Experiment[T])) Option {
+	return func(c configurable) {
+		e, ok := c.(*Experiment[T])
+		if !ok {
+			panic(fmt.Sprintf("scientist: option for %T applied to %T", e, c))
+		}
+		fn(e)
+	}
+}
+
+func WithControl[T any](fn func(ctx context.Context) (T, error)) Option {
+	return WithSetup(func(e *Experiment[T]) { e.Use(fn) })
+}
+
+func WithCandidate(fn func(ctx context.Context) (any, error)) Option {
+	return WithBehavior(candidateBehavior, fn)
+}
+
+func WithBehavior(name string, fn func(ctx context.Context) (any, error)) Option {
+	return func(c configurable) { c.Behavior(name, fn) }
+}
+
+// WithWeight sets the weight of a behavior added by an earlier option. It
+// panics when there's no such behavior or the weight is negative.
+func WithWeight(name string, weight float64) Option {
+	return func(c configurable) { must(c.Weight(name, weight)) }
+}
+
+// WithDisabledBehavior disables a behavior added by an earlier option. It
+// panics when there's no such behavior.
+func WithDisabledBehavior(name string) Option {
+	return func(c configurable) { must(c.DisableBehavior(name)) }
+}
+
+func must(err error) {
+	if err != nil {
+		panic("scientist: " + err.Error())
+	}
+}
+
+func WithComparator[T any](fn func(control T, candidate any) (bool, error)) Option {
+	return WithSetup(func(e *Experiment[T]) { e.Compare(fn) })
+}
+
+func WithComparatorContext[T any](fn func(ctx context.Context, control T, candidate any) (bool, error)) Option {
+	return WithSetup(func(e *Experiment[T]) { e.CompareContext(fn) })
+}
+
+func WithIgnore[T any](fn func(control T, candidate any) (bool, error)) Option {
+	return WithSetup(func(e *Experiment[T]) { e.Ignore(fn) })
+}
+
+func WithIgnoreContext[T any](fn func(ctx context.Context, control T, candidate any) (bool, error)) Option {
+	return WithSetup(func(e *Experiment[T]) { e.IgnoreContext(fn) })
+}
+
+func WithCleaner(fn func(v any) (interface{}, error)) Option {
+	return func(c configurable) { c.Clean(fn) }
+}
+
+func WithPublisher[T any](fn func(*Result[T]) error) Option {
+	return WithSetup(func(e *Experiment[T]) { e.Publish(fn) })
+}
+
+func WithPublisherContext[T any](fn func(ctx context.Context, r *Result[T]) error) Option {
+	return WithSetup(func(e *Experiment[T]) { e.PublishContext(fn) })
+}
+
+func WithErrorReporter(fn func(...ResultError)) Option {
+	return func(c configurable) { c.ReportErrors(fn) }
+}
+
+func WithErrorReporterContext(fn func(ctx context.Context, errs ...ResultError)) Option {
+	return func(c configurable) { c.ReportErrorsContext(fn) }
+}
+
+func WithRunIf(fn func() (bool, error)) Option {
+	return func(c configurable) { c.RunIf(fn) }
+}
+
+func WithRunIfContext[T any](fn func(ctx context.Context, e *Experiment[T]) (bool, error)) Option {
+	return WithSetup(func(e *Experiment[T]) { e.RunIfContext(fn) })
+}
+
+func WithEnabler(enablers ...Enabler) Option {
+	return func(c configurable) { c.Enable(enablers...) }
+}
+
+func WithBeforeRun(fn func() error) Option {
+	return func(c configurable) { c.BeforeRun(fn) }
+}
+
+func WithBeforeRunContext[T any](fn func(ctx context.Context, e *Experiment[T]) error) Option {
+	return WithSetup(func(e *Experiment[T]) { e.BeforeRunContext(fn) })
+}
+
+// WithSynchronous waits for the candidates before Run returns.
+func WithSynchronous(synchronous bool) Option {
+	return func(c configurable) { c.setSynchronous(synchronous) }
+}
+
+// WithContext adds to the experiment's Context.
+func WithContext(context map[string]string) Option {
+	return func(c configurable) { c.setContext(context) }
+}
+
+func WithTimeout(d time.Duration) Option {
+	return func(c configurable) { c.Timeout(d) }
+}
+
+func WithConfig(cfg *Config) Option {
+	return func(c configurable) { c.Configure(cfg) }
+}
+
+func WithFlags(p FlagProvider) Option {
+	return func(c configurable) { c.Flags(p) }
+}
+
+func WithSample(k int) Option {
+	return func(c configurable) { c.Sample(k) }
+}
+
+func WithOrder(o Ordering) Option {
+	return func(c configurable) { c.Order(o) }
+}
+
+func WithBreaker(cfg BreakerConfig) Option {
+	return func(c configurable) { c.Breaker(cfg) }
+}
+
+func WithBreakerChange(fn func(BreakerEvent)) Option {
+	return func(c configurable) { c.OnBreakerChange(fn) }
+}
+
+func WithGuardrail(cfg GuardrailConfig) Option {
+	return func(c configurable) { c.Guardrail(cfg) }
+}
+
+func WithShutdown(fn func(Shutdown)) Option {
+	return func(c configurable) { c.OnShutdown(fn) }
+}
+
+func WithLatency(budget LatencyBudget) Option {
+	return func(c configurable) { c.Latency(budget) }
+}
+
+func WithMeasureResources(enabled bool) Option {
+	return func(c configurable) { c.MeasureResources(enabled) }
+}
+
+func WithCapture(capture *Capture) Option {
+	return func(c configurable) { c.Capture(capture) }
+}
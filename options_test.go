@@ -0,0 +1,141 @@
+package scientist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	var reported []ResultError
+	defaults := Options(
+		WithSynchronous(true),
+		WithContext(map[string]string{"team": "widgets"}),
+		WithTimeout(time.Second),
+		WithErrorReporter(func(errs ...ResultError) {
+			reported = append(reported, errs...)
+		}),
+	)
+
+	var result *Result[int]
+	e := New[int]("options",
+		defaults,
+		WithControl(func(ctx context.Context) (int, error) {
+			return 1, nil
+		}),
+		WithCandidate(func(ctx context.Context) (any, error) {
+			return 2, nil
+		}),
+		WithComparator(func(control int, candidate any) (bool, error) {
+			return candidate.(int)%2 == control%2, nil
+		}),
+		WithBeforeRun(func() error { return nil }),
+		WithPublisher(func(r *Result[int]) error {
+			result = r
+			return nil
+		}),
+	)
+
+	if !e.Synchronous || e.timeout != time.Second || e.Context["team"] != "widgets" {
+		t.Errorf("expected the bundled options to be applied, got %+v", e)
+	}
+
+	v, err := e.Run(context.Background())
+	if v != 1 || err != nil {
+		t.Errorf("unexpected control result: %d, %v", v, err)
+	}
+	if result == nil || !result.IsMismatched() {
+		t.Errorf("expected the custom comparator to mismatch, got %+v", result)
+	}
+	if len(reported) != 0 {
+		t.Errorf("unexpected errors: %v", reported)
+	}
+
+	// the bundle works for experiments of any type
+	s := New[string]("options", defaults)
+	if !s.Synchronous || s.Context["team"] != "widgets" {
+		t.Errorf("expected the bundle to apply to a string experiment, got %+v", s)
+	}
+}
+
+func TestWithSetupWrongType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic applying an int option to a string experiment")
+		}
+	}()
+
+	New[string]("options", WithPublisher(func(r *Result[int]) error { return nil }))
+}
+
+func TestContextOptions(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request")
+	seen := make(map[string]any)
+	hook := func(name string, ctx context.Context) {
+		seen[name] = ctx.Value(key{})
+	}
+
+	e := New[int]("options",
+		WithSynchronous(true),
+		WithControl(func(ctx context.Context) (int, error) {
+			return 1, nil
+		}),
+		WithBehavior("api", func(ctx context.Context) (any, error) {
+			return 2, nil
+		}),
+		WithBehavior("raw-sql", func(ctx context.Context) (any, error) {
+			return 1, nil
+		}),
+		WithBehavior("cache", func(ctx context.Context) (any, error) {
+			return 1, nil
+		}),
+		WithWeight("api", 0),
+		WithDisabledBehavior("cache"),
+		WithSample(1),
+		WithRunIfContext(func(ctx context.Context, e *Experiment[int]) (bool, error) {
+			hook("run_if", ctx)
+			return true, nil
+		}),
+		WithBeforeRunContext(func(ctx context.Context, e *Experiment[int]) error {
+			hook("before_run", ctx)
+			return nil
+		}),
+		WithComparatorContext(func(ctx context.Context, control int, candidate any) (bool, error) {
+			hook("compare", ctx)
+			return false, nil
+		}),
+		WithIgnoreContext(func(ctx context.Context, control int, candidate any) (bool, error) {
+			hook("ignore", ctx)
+			return false, nil
+		}),
+		WithPublisherContext(func(ctx context.Context, r *Result[int]) error {
+			hook("publish", ctx)
+			if len(r.Candidates) != 1 || r.Candidates[0].Name != "raw-sql" {
+				t.Errorf("expected only raw-sql to run, got %v", r.Candidates)
+			}
+			return errors.New("publish failed")
+		}),
+		WithErrorReporterContext(func(ctx context.Context, errs ...ResultError) {
+			hook("report_errors", ctx)
+		}),
+	)
+	e.Run(ctx)
+
+	for _, name := range []string{"run_if", "before_run", "compare", "ignore", "publish", "report_errors"} {
+		if seen[name] != "request" {
+			t.Errorf("expected %s to get the run's context, got %v", name, seen[name])
+		}
+	}
+}
+
+func TestWithWeightUnknownBehavior(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic weighting an unknown behavior")
+		}
+	}()
+
+	New[int]("options", WithWeight("nope", 1))
+}
@@ -0,0 +1,85 @@
+package scientist
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// ControlOrder is when the control runs relative to the candidates.
+type ControlOrder int
+
+const (
+	// ControlFirst runs the control, then the candidates concurrently.
+	ControlFirst ControlOrder = iota
+	// ControlLast runs the candidates one after another, then the control.
+	ControlLast
+	// ControlInterleaved runs the control at a random position among the
+	// candidates, one behavior after another.
+	ControlInterleaved
+)
+
+func (o ControlOrder) String() string {
+	switch o {
+	case ControlFirst:
+		return "first"
+	case ControlLast:
+		return "last"
+	case ControlInterleaved:
+		return "interleaved"
+	default:
+		return fmt.Sprintf("ControlOrder(%d)", int(o))
+	}
+}
+
+// Ordering configures the order behaviors run in.
+type Ordering struct {
+	Control ControlOrder
+	// Fixed runs the candidates in the order they were added instead of a
+	// random order.
+	Fixed bool
+	// Seed for the random order and the candidates picked by Sample. Zero
+	// picks a new seed every run; the seed used is recorded on the Result, so
+	// a run's order and candidates can be reproduced by setting it here.
+	Seed int64
+}
+
+// Order sets the order behaviors run in. By default the control runs first
+// and the candidates start in a random order.
+func (e *Experiment[T]) Order(o Ordering) {
+	e.ordering = o
+}
+
+// seed returns the seed of a run, or zero when nothing is random. sampled is
+// set when candidates are sampled.
+func (o Ordering) seed(sampled bool) int64 {
+	if o.Fixed && o.Control != ControlInterleaved && !sampled {
+		return 0
+	}
+	if o.Seed != 0 {
+		return o.Seed
+	}
+	for {
+		if seed := rand.Int63(); seed != 0 {
+			return seed
+		}
+	}
+}
+
+// arrange returns the candidates in the order to run them in and how many of
+// them run before the control, using r for the random order. The behaviors
+// are not modified.
+func (o Ordering) arrange(behaviors []*behavior[any], r *rand.Rand) ([]*behavior[any], int) {
+	arranged := behaviors
+	if !o.Fixed {
+		arranged = shuffle(r, behaviors)
+	}
+
+	switch o.Control {
+	case ControlLast:
+		return arranged, len(arranged)
+	case ControlInterleaved:
+		return arranged, r.Intn(len(arranged) + 1)
+	default:
+		return arranged, 0
+	}
+}
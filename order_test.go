@@ -0,0 +1,153 @@
+package scientist
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestShuffle(t *testing.T) {
+	items := []int{0, 1, 2}
+	r := rand.New(rand.NewSource(1))
+
+	seen := map[string]int{}
+	for i := 0; i < 6000; i++ {
+		seen[fmt.Sprint(shuffle(r, items))]++
+	}
+
+	if !reflect.DeepEqual(items, []int{0, 1, 2}) {
+		t.Errorf("expected shuffle not to modify its input, got %v", items)
+	}
+	if len(seen) != 6 {
+		t.Fatalf("expected all 6 permutations, got %v", seen)
+	}
+	for perm, n := range seen {
+		if n < 800 || n > 1200 {
+			t.Errorf("expected about 1000 of each permutation, got %d of %s", n, perm)
+		}
+	}
+}
+
+// orderedExperiment records the order its behaviors run in.
+func orderedExperiment(order *[]string) *Experiment[int] {
+	var mu sync.Mutex
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		*order = append(*order, name)
+	}
+
+	e := New[int]("order")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		record("control")
+		return 1, nil
+	})
+	for _, name := range []string{"a", "b", "c", "d"} {
+		name := name
+		e.Behavior(name, func(ctx context.Context) (any, error) {
+			record(name)
+			return 1, nil
+		})
+	}
+	return e
+}
+
+func TestOrderFixed(t *testing.T) {
+	var order []string
+	e := orderedExperiment(&order)
+	e.Order(Ordering{Control: ControlLast, Fixed: true})
+
+	var seed int64 = -1
+	e.Publish(func(r *Result[int]) error {
+		seed = r.Seed
+		return nil
+	})
+
+	e.Run(context.Background())
+	if got := strings.Join(order, ","); got != "a,b,c,d,control" {
+		t.Errorf("expected fixed order with the control last, got %s", got)
+	}
+	if seed != 0 {
+		t.Errorf("expected no seed for a fixed order, got %d", seed)
+	}
+}
+
+func TestOrderSeed(t *testing.T) {
+	var order []string
+	e := orderedExperiment(&order)
+	e.Order(Ordering{Control: ControlInterleaved})
+
+	var seed int64
+	e.Publish(func(r *Result[int]) error {
+		seed = r.Seed
+		return nil
+	})
+
+	orders := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		order = nil
+		e.Run(context.Background())
+		orders[strings.Join(order, ",")] = true
+	}
+	if len(orders) < 2 {
+		t.Errorf("expected random orders, got %v", orders)
+	}
+
+	first := strings.Join(order, ",")
+	if seed == 0 {
+		t.Fatal("expected the seed to be recorded")
+	}
+
+	e.Order(Ordering{Control: ControlInterleaved, Seed: seed})
+	for i := 0; i < 3; i++ {
+		order = nil
+		e.Run(context.Background())
+		if got := strings.Join(order, ","); got != first {
+			t.Errorf("expected seed %d to reproduce %s, got %s", seed, first, got)
+		}
+	}
+}
+
+func TestOrderControlFirst(t *testing.T) {
+	var order []string
+	e := orderedExperiment(&order)
+	e.Order(Ordering{Fixed: true})
+
+	e.Run(context.Background())
+	if len(order) != 5 || order[0] != "control" {
+		t.Errorf("expected the control first, got %v", order)
+	}
+}
+
+func TestOrderSeedSample(t *testing.T) {
+	var order []string
+	e := orderedExperiment(&order)
+	e.Order(Ordering{Control: ControlLast, Fixed: true})
+	e.Sample(2)
+
+	var seed int64
+	e.Publish(func(r *Result[int]) error {
+		seed = r.Seed
+		return nil
+	})
+
+	e.Run(context.Background())
+	first := strings.Join(order, ",")
+	if seed == 0 {
+		t.Fatal("expected the seed of a sampled run to be recorded")
+	}
+
+	e.Order(Ordering{Control: ControlLast, Fixed: true, Seed: seed})
+	for i := 0; i < 3; i++ {
+		order = nil
+		e.Run(context.Background())
+		if got := strings.Join(order, ","); got != first {
+			t.Errorf("expected seed %d to pick %s, got %s", seed, first, got)
+		}
+	}
+}
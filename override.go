@@ -0,0 +1,39 @@
+package scientist
+
+import "sync/atomic"
+
+// Overrides change how every experiment runs, for tests. See package
+// scientisttest.
+type Overrides struct {
+	// Synchronous waits for the candidates of every experiment before Run
+	// returns.
+	Synchronous bool
+	// Enabled runs and publishes the candidates of every experiment,
+	// regardless of RunIf, Enable, configuration files and flags.
+	Enabled bool
+}
+
+var overrides atomic.Pointer[Overrides]
+
+// Override changes the overrides with fn and returns a function restoring
+// the previous ones.
+func Override(fn func(o *Overrides)) (restore func()) {
+	for {
+		prev := overrides.Load()
+		next := &Overrides{}
+		if prev != nil {
+			*next = *prev
+		}
+		fn(next)
+		if overrides.CompareAndSwap(prev, next) {
+			return func() { overrides.Store(prev) }
+		}
+	}
+}
+
+func currentOverrides() Overrides {
+	if o := overrides.Load(); o != nil {
+		return *o
+	}
+	return Overrides{}
+}
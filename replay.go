@@ -0,0 +1,250 @@
+package scientist
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxRecordBytes is the longest corpus line Replay reads.
+const maxRecordBytes = 16 << 20
+
+// ReplayReport summarizes the replay of a capture corpus.
+type ReplayReport struct {
+	Experiment string
+	Replayed   int
+	Matched    int
+	Mismatched int
+	Ignored    int
+	// Skipped counts the records that couldn't be decoded, see Errors.
+	Skipped int
+
+	Mismatches []ReplayMismatch
+	Errors     []error
+}
+
+// ReplayMismatch is a record the candidate mismatched.
+type ReplayMismatch struct {
+	// Line of the record in the corpus, from 1.
+	Line   int
+	Record CaptureRecord
+	// Value and Err returned by the candidate.
+	Value any
+	Err   error
+}
+
+// String formats the report as text: a summary line, then the mismatches.
+func (r *ReplayReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: replayed %d, matched %d, mismatched %d, ignored %d, skipped %d\n",
+		r.Experiment, r.Replayed, r.Matched, r.Mismatched, r.Ignored, r.Skipped)
+
+	for _, m := range r.Mismatches {
+		fmt.Fprintf(&b, "line %d: inputs %s\n", m.Line, inputsString(m.Record.Inputs))
+		if m.Record.Error != "" {
+			fmt.Fprintf(&b, "  - error: %s\n", m.Record.Error)
+		} else {
+			fmt.Fprintf(&b, "  - %s\n", m.Record.Value)
+		}
+		if m.Err != nil {
+			fmt.Fprintf(&b, "  + error: %v\n", m.Err)
+		} else if v, err := json.Marshal(m.Value); err == nil {
+			fmt.Fprintf(&b, "  + %s\n", v)
+		} else {
+			fmt.Fprintf(&b, "  + %#v\n", m.Value)
+		}
+	}
+	for _, err := range r.Errors {
+		fmt.Fprintf(&b, "skipped %v\n", err)
+	}
+	return b.String()
+}
+
+func inputsString(inputs []json.RawMessage) string {
+	s := make([]string, len(inputs))
+	for i, input := range inputs {
+		s[i] = string(input)
+	}
+	return "(" + strings.Join(s, ", ") + ")"
+}
+
+// Replay1 runs candidate on the inputs of the experiment name's records in
+// corpus, read with a Capture, and compares its results to the recorded
+// control's. The control doesn't run. Options configure the comparison like in
+// New, with WithComparator, WithIgnore or WithPublisher for example.
+//
+// Recorded values are decoded from JSON, and the candidate's values are
+// encoded and decoded too before the comparison, so fields JSON leaves out
+// don't cause mismatches.
+func Replay1[A, R any](ctx context.Context, corpus io.Reader, name string, candidate func(ctx context.Context, a A) (R, error), opts ...Option) (*ReplayReport, error) {
+	return replay(ctx, corpus, name, fixed(decode[A]), func(ctx context.Context, args []any) (R, error) {
+		return candidate(ctx, arg[A](args, 0))
+	}, opts)
+}
+
+// Replay2 is Replay1 for functions of two arguments.
+func Replay2[A, B, R any](ctx context.Context, corpus io.Reader, name string, candidate func(ctx context.Context, a A, b B) (R, error), opts ...Option) (*ReplayReport, error) {
+	return replay(ctx, corpus, name, fixed(decode[A], decode[B]), func(ctx context.Context, args []any) (R, error) {
+		return candidate(ctx, arg[A](args, 0), arg[B](args, 1))
+	}, opts)
+}
+
+// Replay3 is Replay1 for functions of three arguments.
+func Replay3[A, B, C, R any](ctx context.Context, corpus io.Reader, name string, candidate func(ctx context.Context, a A, b B, c C) (R, error), opts ...Option) (*ReplayReport, error) {
+	return replay(ctx, corpus, name, fixed(decode[A], decode[B], decode[C]), func(ctx context.Context, args []any) (R, error) {
+		return candidate(ctx, arg[A](args, 0), arg[B](args, 1), arg[C](args, 2))
+	}, opts)
+}
+
+// ReplayVariadic is Replay1 for variadic functions, like those wrapped with
+// WrapVariadic or the variadic methods of a Shadow, whose arguments are
+// recorded one by one.
+func ReplayVariadic[A, R any](ctx context.Context, corpus io.Reader, name string, candidate func(ctx context.Context, args ...A) (R, error), opts ...Option) (*ReplayReport, error) {
+	return replay(ctx, corpus, name, variadic(decode[A]), func(ctx context.Context, args []any) (R, error) {
+		typed := make([]A, len(args))
+		for i := range args {
+			typed[i] = arg[A](args, i)
+		}
+		return candidate(ctx, typed...)
+	}, opts)
+}
+
+type decoder func(raw json.RawMessage) (any, error)
+
+// decoders returns the decoders of a record's n inputs.
+type decoders func(n int) ([]decoder, error)
+
+func fixed(ds ...decoder) decoders {
+	return func(n int) ([]decoder, error) {
+		if n != len(ds) {
+			return nil, fmt.Errorf("expected %d inputs, got %d", len(ds), n)
+		}
+		return ds, nil
+	}
+}
+
+func variadic(d decoder) decoders {
+	return func(n int) ([]decoder, error) {
+		ds := make([]decoder, n)
+		for i := range ds {
+			ds[i] = d
+		}
+		return ds, nil
+	}
+}
+
+func decode[A any](raw json.RawMessage) (any, error) {
+	var a A
+	err := json.Unmarshal(raw, &a)
+	return a, err
+}
+
+func replay[R any](ctx context.Context, corpus io.Reader, name string, decoders decoders, candidate func(ctx context.Context, args []any) (R, error), opts []Option) (*ReplayReport, error) {
+	e := New[R](name, opts...)
+	report := &ReplayReport{Experiment: name}
+
+	scanner := bufio.NewScanner(corpus)
+	scanner.Buffer(nil, maxRecordBytes)
+	for line := 1; scanner.Scan(); line++ {
+		var rec CaptureRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			report.skip(line, err)
+			continue
+		}
+		if rec.Experiment != name {
+			continue
+		}
+
+		r, err := replayRecord(ctx, e, rec, decoders, candidate)
+		if err != nil {
+			report.skip(line, err)
+			continue
+		}
+
+		report.Replayed++
+		o := r.Candidates[0]
+		switch {
+		case o.Mismatched:
+			report.Mismatched++
+			report.Mismatches = append(report.Mismatches, ReplayMismatch{Line: line, Record: rec, Value: o.Value, Err: o.Err})
+		case o.Ignored:
+			report.Ignored++
+		default:
+			report.Matched++
+		}
+	}
+	return report, scanner.Err()
+}
+
+func (r *ReplayReport) skip(line int, err error) {
+	r.Skipped++
+	r.Errors = append(r.Errors, fmt.Errorf("line %d: %w", line, err))
+}
+
+// replayRecord runs the candidate on the record's inputs and publishes the
+// result of comparing it to the recorded control.
+func replayRecord[R any](ctx context.Context, e *Experiment[R], rec CaptureRecord, decoders decoders, candidate func(ctx context.Context, args []any) (R, error)) (*Result[R], error) {
+	ds, err := decoders(len(rec.Inputs))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]any, len(ds))
+	for i, decode := range ds {
+		arg, err := decode(rec.Inputs[i])
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		args[i] = arg
+	}
+
+	control := &Observation[R, R]{Experiment: e, Name: controlBehavior, Started: rec.Time}
+	if rec.Error != "" {
+		control.Err = errors.New(rec.Error)
+	} else if len(rec.Value) > 0 {
+		if err := json.Unmarshal(rec.Value, &control.Value); err != nil {
+			return nil, fmt.Errorf("control value: %w", err)
+		}
+	}
+
+	b := &behavior[any]{name: candidateBehavior, fn: func(ctx context.Context) (any, error) {
+		v, err := candidate(ctx, args)
+		if err != nil {
+			return v, err
+		}
+		return roundTrip(v), nil
+	}}
+
+	r := &Result[R]{
+		Experiment: e,
+		Context:    rec.Context,
+		Inputs:     args,
+		Control:    control,
+	}
+	r.Candidates = append(r.Candidates, e.observeCandidate(ctx, b, e.timeout, 0))
+	r.finalize(ctx)
+
+	if err := e.publisher(ctx, r); err != nil {
+		r.addError("publish", err)
+	}
+	if len(r.Errors) > 0 {
+		e.errorReporter(ctx, r.Errors...)
+	}
+	return r, nil
+}
+
+// roundTrip encodes v to JSON and decodes it back, like recorded values are.
+func roundTrip[R any](v R) R {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var decoded R
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return v
+	}
+	return decoded
+}
@@ -0,0 +1,131 @@
+package scientist
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	capture, err := NewCapture(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	find := Wrap1("find-user", func(ctx context.Context, id int64) (*user, error) {
+		if id == 0 {
+			return nil, errors.New("not found")
+		}
+		return &user{ID: id, Name: "hubot"}, nil
+	}, nil, WithCapture(capture), WithRunIf(func() (bool, error) {
+		return false, nil
+	}))
+	for _, id := range []int64{1, 2, 3, 0} {
+		find(context.Background(), id)
+	}
+	capture.Close()
+
+	// records of other experiments are left alone, broken ones skipped
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"experiment":"other","inputs":["x"]}` + "\n")
+	f.WriteString(`{"experiment":"find-user","inputs":["x"]}` + "\n")
+	f.WriteString("not json\n")
+	f.Close()
+
+	corpus, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer corpus.Close()
+
+	var published int
+	report, err := Replay1(context.Background(), corpus, "find-user", func(ctx context.Context, id int64) (*user, error) {
+		switch id {
+		case 0:
+			return nil, errors.New("not found")
+		case 2:
+			return &user{ID: id, Name: "monalisa"}, nil
+		case 3:
+			return &user{ID: id, Name: "Hubot"}, nil
+		}
+		return &user{ID: id, Name: "hubot"}, nil
+	},
+		WithIgnore(func(control *user, candidate any) (bool, error) {
+			return strings.EqualFold(control.Name, candidate.(*user).Name), nil
+		}),
+		WithPublisher(func(r *Result[*user]) error {
+			published++
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Replayed != 4 || report.Matched != 2 || report.Mismatched != 1 || report.Ignored != 1 || report.Skipped != 2 || published != 4 {
+		t.Errorf("unexpected report: %+v, published %d", report, published)
+	}
+	if len(report.Mismatches) != 1 || report.Mismatches[0].Line != 2 || report.Mismatches[0].Value.(*user).Name != "monalisa" {
+		t.Fatalf("unexpected mismatches: %+v", report.Mismatches)
+	}
+
+	expected := `find-user: replayed 4, matched 2, mismatched 1, ignored 1, skipped 2
+line 2: inputs (2)
+  - {"ID":2,"Name":"hubot"}
+  + {"ID":2,"Name":"monalisa"}
+skipped line 6: input 0: json: `
+	if actual := report.String(); !strings.HasPrefix(actual, expected) || !strings.Contains(actual, "\nskipped line 7: ") {
+		t.Errorf("expected:\n%s...\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestReplayVariadic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	capture, err := NewCapture(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := func(ctx context.Context, ns ...int) (int, error) {
+		total := 0
+		for _, n := range ns {
+			total += n
+		}
+		return total, nil
+	}
+	wrapped := WrapVariadic("sum", sum, sum, WithCapture(capture))
+	wrapped(context.Background())
+	wrapped(context.Background(), 1)
+	wrapped(context.Background(), 1, 2, 3)
+	capture.Close()
+
+	corpus, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer corpus.Close()
+
+	report, err := ReplayVariadic(context.Background(), corpus, "sum", func(ctx context.Context, ns ...int) (int, error) {
+		if len(ns) > 2 {
+			return 0, nil
+		}
+		return sum(ctx, ns...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Replayed != 3 || report.Matched != 2 || report.Mismatched != 1 || report.Skipped != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(report.Mismatches) != 1 || len(report.Mismatches[0].Record.Inputs) != 3 {
+		t.Errorf("unexpected mismatches: %+v", report.Mismatches)
+	}
+}
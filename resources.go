@@ -0,0 +1,63 @@
+package scientist
+
+import (
+	"runtime"
+	"runtime/metrics"
+	"time"
+)
+
+// MeasureResources records the CPU time of every observation in
+// Observation.CPUTime, and the heap allocations of each behavior in Metrics.
+// CPU time is only measured on Linux, where each behavior is locked to its OS
+// thread while it runs; work done in other goroutines is not counted.
+// Allocations are read from process wide counters that the runtime updates as
+// it refills its allocation caches, and include the allocations of anything
+// running concurrently, like other candidates, so they are only reported
+// averaged over many runs, by BehaviorMetrics.BytesPerRun and ObjectsPerRun.
+func (e *Experiment[T]) MeasureResources(enabled bool) {
+	e.measureResources = enabled
+}
+
+var allocMetrics = []string{"/gc/heap/allocs:bytes", "/gc/heap/allocs:objects"}
+
+// resources are the counters sampled before and after a behavior runs.
+type resources struct {
+	cpu     time.Duration
+	cpuOK   bool
+	bytes   uint64
+	objects uint64
+}
+
+func sampleResources() resources {
+	samples := []metrics.Sample{{Name: allocMetrics[0]}, {Name: allocMetrics[1]}}
+	metrics.Read(samples)
+
+	r := resources{}
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		r.bytes = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		r.objects = samples[1].Value.Uint64()
+	}
+	r.cpu, r.cpuOK = threadCPUTime()
+	return r
+}
+
+// measureResources locks the goroutine to its thread and returns a function
+// recording the resources used since into o.
+func measureResources[TE any, TVal any](o *Observation[TE, TVal]) func() {
+	runtime.LockOSThread()
+	before := sampleResources()
+
+	return func() {
+		after := sampleResources()
+		runtime.UnlockOSThread()
+
+		if before.cpuOK && after.cpuOK {
+			o.CPUTime = after.cpu - before.cpu
+		}
+		o.allocBytes = after.bytes - before.bytes
+		o.allocObjects = after.objects - before.objects
+		o.ResourcesMeasured = true
+	}
+}
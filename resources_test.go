@@ -0,0 +1,80 @@
+package scientist
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+var sink []byte
+
+func TestMeasureResources(t *testing.T) {
+	e := New[int]("resources")
+	e.Synchronous = true
+	e.MeasureResources(true)
+	e.Use(func(ctx context.Context) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 1, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		for i := 0; i < 100; i++ {
+			sink = make([]byte, 1024)
+		}
+		spin(20 * time.Millisecond)
+		return 1, nil
+	})
+
+	metrics := NewMetrics()
+	var result *Result[int]
+	e.Publish(func(r *Result[int]) error {
+		result = r
+		RecordMetrics(metrics, r)
+		return nil
+	})
+	e.Run(context.Background())
+
+	control, candidate := result.Control, result.Candidates[0]
+	if !control.ResourcesMeasured || !candidate.ResourcesMeasured {
+		t.Fatalf("expected resources to be measured")
+	}
+
+	if runtime.GOOS == "linux" {
+		if candidate.CPUTime < 5*time.Millisecond {
+			t.Errorf("expected the candidate to use CPU, got %s", candidate.CPUTime)
+		}
+		if control.CPUTime > 5*time.Millisecond {
+			t.Errorf("expected a sleeping control not to use CPU, got %s", control.CPUTime)
+		}
+	}
+
+	// allocations are counted as the runtime refills its caches, so they
+	// are only reported in aggregate
+	b := metrics.Snapshot()["resources"].Behaviors["candidate"]
+	if b.Measured != 1 || b.BytesPerRun() < 50*1024 || b.ObjectsPerRun() < 50 || b.CPUTime.Count != 1 {
+		t.Errorf("unexpected candidate metrics: %+v", b)
+	}
+}
+
+func TestResourcesNotMeasured(t *testing.T) {
+	e := New[int]("resources")
+	e.Synchronous = true
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		return 1, nil
+	})
+	e.Publish(func(r *Result[int]) error {
+		if r.Control.ResourcesMeasured || r.Candidates[0].ResourcesMeasured {
+			t.Errorf("did not expect resources to be measured")
+		}
+		return nil
+	})
+	e.Run(context.Background())
+}
+
+func spin(d time.Duration) {
+	for start := time.Now(); time.Since(start) < d; {
+	}
+}
@@ -1,13 +1,32 @@
 package scientist
 
+import "context"
+
 type Result[T any] struct {
 	Experiment   *Experiment[T]
+	Context      map[string]string
 	Control      *Observation[T, T]
 	Observations []*Observation[T, any]
 	Candidates   []*Observation[T, any]
 	Ignored      []*Observation[T, any]
 	Mismatched   []*Observation[T, any]
+	Slow         []*Observation[T, any]
 	Errors       []ResultError
+
+	// Tags are the typed values of Context: the experiment's context merged
+	// with the tags of the run, from WithTag and Tag.
+	Tags map[string]any
+
+	// Inputs of the run, recorded by the Wrap adapters or the Inputs
+	// option.
+	Inputs []any
+
+	// Seed of the random order the behaviors ran in and of the candidates
+	// picked by Sample, zero if nothing was random. See Experiment.Order.
+	Seed int64
+
+	// probes are the circuit breaker probes of the candidates, by name.
+	probes map[string]uint64
 }
 
 func (r Result[T]) IsMatched() bool {
@@ -25,13 +44,29 @@ func (r Result[T]) IsIgnored() bool {
 	return len(r.Ignored) > 0
 }
 
-func (r *Result[T]) finalize() {
+// IsSlow reports whether any candidate ran over the latency budget,
+// regardless of its value.
+func (r Result[T]) IsSlow() bool {
+	return len(r.Slow) > 0
+}
+
+func (r *Result[T]) finalize(ctx context.Context) {
 	if r.Control == nil {
 		return
 	}
 
+	if l := r.Experiment.latency; l != nil {
+		for _, candidate := range r.Candidates {
+			if l.slow(r.Control.Runtime, candidate.Runtime) {
+				r.Slow = append(r.Slow, candidate)
+				candidate.Slow = true
+			}
+		}
+		l.record(r.Control.Runtime)
+	}
+
 	for _, candidate := range r.Candidates {
-		ok, err := r.matching(r.Control, candidate)
+		ok, err := r.matching(ctx, r.Control, candidate)
 		if err != nil {
 			ok = false
 			r.addError("compare", err)
@@ -41,7 +76,7 @@ func (r *Result[T]) finalize() {
 			continue
 		}
 
-		ignored, err := r.ignoring(r.Control, candidate)
+		ignored, err := r.ignoring(ctx, r.Control, candidate)
 		if err != nil {
 			ignored = false
 			r.addError("ignore", err)
@@ -61,10 +96,10 @@ func (r *Result[T]) addError(operation string, err error) {
 	r.Errors = append(r.Errors, ResultError{Operation: operation, Experiment: r.Experiment.Name, Err: err})
 }
 
-func (r *Result[T]) matching(control *Observation[T, T], candidate *Observation[T, any]) (bool, error) {
+func (r *Result[T]) matching(ctx context.Context, control *Observation[T, T], candidate *Observation[T, any]) (bool, error) {
 	// neither returned errors
 	if control.Err == nil && candidate.Err == nil {
-		return r.Experiment.comparator(control.Value, candidate.Value)
+		return r.Experiment.comparator(ctx, control.Value, candidate.Value)
 	}
 
 	// both returned errors
@@ -76,9 +111,9 @@ func (r *Result[T]) matching(control *Observation[T, T], candidate *Observation[
 	return false, nil
 }
 
-func (r *Result[T]) ignoring(control *Observation[T, T], candidate *Observation[T, any]) (bool, error) {
+func (r *Result[T]) ignoring(ctx context.Context, control *Observation[T, T], candidate *Observation[T, any]) (bool, error) {
 	for _, i := range r.Experiment.ignores {
-		ok, err := i(control.Value, candidate.Value)
+		ok, err := i(ctx, control.Value, candidate.Value)
 		if err != nil {
 			return false, err
 		}
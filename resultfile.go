@@ -0,0 +1,148 @@
+package scientist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ResultRecord is a published result as a line of a results file, written
+// by a ResultFile and summarized by the scientist command.
+type ResultRecord struct {
+	Experiment string                     `json:"experiment"`
+	Time       time.Time                  `json:"time"`
+	Context    map[string]string          `json:"context,omitempty"`
+	Tags       map[string]json.RawMessage `json:"tags,omitempty"`
+	Matched    bool                       `json:"matched"`
+	Mismatched bool                       `json:"mismatched,omitempty"`
+	Ignored    bool                       `json:"ignored,omitempty"`
+	Control    ObservationRecord          `json:"control"`
+	Candidates []ObservationRecord        `json:"candidates"`
+	Errors     []ErrorRecord              `json:"errors,omitempty"`
+}
+
+// ObservationRecord is an observation of a ResultRecord. Values are the
+// cleaned values, see Clean.
+type ObservationRecord struct {
+	Name       string          `json:"name"`
+	Runtime    time.Duration   `json:"runtime"`
+	Value      json.RawMessage `json:"value,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Mismatched bool            `json:"mismatched,omitempty"`
+	Ignored    bool            `json:"ignored,omitempty"`
+	TimedOut   bool            `json:"timed_out,omitempty"`
+	Panicked   bool            `json:"panicked,omitempty"`
+	Slow       bool            `json:"slow,omitempty"`
+}
+
+// ErrorRecord is a ResultError of a ResultRecord.
+type ErrorRecord struct {
+	Operation string `json:"operation"`
+	Error     string `json:"error"`
+}
+
+// NewResultRecord returns the record of r. Values that can't be encoded to
+// JSON are recorded as strings, formatted with %#v.
+func NewResultRecord[T any](r *Result[T]) ResultRecord {
+	rec := ResultRecord{
+		Experiment: r.Experiment.Name,
+		Context:    r.Context,
+		Matched:    r.IsMatched(),
+		Mismatched: r.IsMismatched(),
+		Ignored:    r.IsIgnored(),
+		Candidates: make([]ObservationRecord, len(r.Candidates)),
+	}
+	if r.Control != nil {
+		rec.Time = r.Control.Started
+		rec.Control = observationRecord(r.Control)
+	}
+	for i, o := range r.Candidates {
+		rec.Candidates[i] = observationRecord(o)
+	}
+	if len(r.Tags) > 0 {
+		rec.Tags = make(map[string]json.RawMessage, len(r.Tags))
+		for k, v := range r.Tags {
+			rec.Tags[k] = rawJSON(v)
+		}
+	}
+	for _, err := range r.Errors {
+		rec.Errors = append(rec.Errors, ErrorRecord{Operation: err.Operation, Error: err.Err.Error()})
+	}
+	return rec
+}
+
+func observationRecord[TE any, TVal any](o *Observation[TE, TVal]) ObservationRecord {
+	rec := ObservationRecord{
+		Name:       o.Name,
+		Runtime:    o.Runtime,
+		Mismatched: o.Mismatched,
+		Ignored:    o.Ignored,
+		TimedOut:   o.TimedOut,
+		Panicked:   o.Panicked,
+		Slow:       o.Slow,
+	}
+	if o.Err != nil {
+		rec.Error = o.Err.Error()
+		return rec
+	}
+
+	v, err := o.CleanedValue()
+	if err != nil {
+		rec.Error = fmt.Sprintf("clean: %v", err)
+		return rec
+	}
+	rec.Value = rawJSON(v)
+	return rec
+}
+
+func rawJSON(v any) json.RawMessage {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		raw, _ = json.Marshal(fmt.Sprintf("%#v", v))
+	}
+	return raw
+}
+
+// ResultFile appends results to a file, one ResultRecord as JSON per line.
+// It is safe to share between experiments and goroutines.
+type ResultFile struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenResultFile appends to the results file at path, creating it if needed.
+func OpenResultFile(path string) (*ResultFile, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultFile{f: f}, nil
+}
+
+// Write appends a record to the file.
+func (f *ResultFile) Write(rec ResultRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.f.Write(append(line, '\n'))
+	return err
+}
+
+func (f *ResultFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.f.Close()
+}
+
+// FilePublisher returns a Publish callback writing results to f.
+func FilePublisher[T any](f *ResultFile) func(*Result[T]) error {
+	return func(r *Result[T]) error {
+		return f.Write(NewResultRecord(r))
+	}
+}
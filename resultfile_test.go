@@ -0,0 +1,73 @@
+package scientist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResultFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	f, err := OpenResultFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := New[*user]("find-user", WithSynchronous(true), WithPublisher(FilePublisher[*user](f)), WithContext(map[string]string{"host": "a"}))
+	e.Use(func(ctx context.Context) (*user, error) {
+		return &user{ID: 1, Name: "hubot"}, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		return nil, errors.New("not found")
+	})
+	e.Behavior("same", func(ctx context.Context) (any, error) {
+		return &user{ID: 1, Name: "hubot"}, nil
+	})
+	e.Clean(func(v any) (interface{}, error) {
+		if u, ok := v.(*user); ok {
+			return u.Name, nil
+		}
+		return v, nil
+	})
+	if _, err := e.Run(context.Background(), Tag("attempt", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a line, got %q", data)
+	}
+
+	var rec ResultRecord
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Experiment != "find-user" || rec.Time.IsZero() || rec.Context["host"] != "a" || string(rec.Tags["attempt"]) != "2" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	if rec.Matched || !rec.Mismatched || rec.Control.Name != "control" || string(rec.Control.Value) != `"hubot"` {
+		t.Errorf("unexpected control: %+v", rec)
+	}
+
+	candidates := map[string]ObservationRecord{}
+	for _, o := range rec.Candidates {
+		candidates[o.Name] = o
+	}
+	if o := candidates["candidate"]; o.Error != "not found" || o.Value != nil || !o.Mismatched {
+		t.Errorf("unexpected candidate: %+v", o)
+	}
+	if o := candidates["same"]; o.Error != "" || string(o.Value) != `"hubot"` || o.Mismatched {
+		t.Errorf("unexpected candidate: %+v", o)
+	}
+}
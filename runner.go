@@ -0,0 +1,92 @@
+package scientist
+
+import "context"
+
+// Runner is a frozen copy of an experiment, safe for concurrent Run calls.
+// Changing the experiment after Build doesn't affect the runner. Circuit
+// breakers, guardrails and latency windows are shared with the experiment.
+type Runner[T any] struct {
+	e *Experiment[T]
+}
+
+// Build freezes the experiment into a Runner. Share the runner between
+// goroutines instead of the experiment, and pass per-run context with
+// RunContext.
+func (e *Experiment[T]) Build() (*Runner[T], error) {
+	if e.control == nil {
+		return nil, behaviorNotFound(e, controlBehavior)
+	}
+
+	frozen := *e
+	frozen.Context = mergeContext(e.Context)
+	frozen.ignores = append([]func(context.Context, T, any) (bool, error)(nil), e.ignores...)
+	frozen.behaviors = make([]*behavior[any], len(e.behaviors))
+	for i, b := range e.behaviors {
+		copied := newBehavior(b.name, b.fn)
+		copied.setWeight(b.getWeight())
+		copied.disabled.Store(b.disabled.Load())
+		frozen.behaviors[i] = copied
+	}
+	return &Runner[T]{e: &frozen}, nil
+}
+
+func (r *Runner[T]) Name() string {
+	return r.e.Name
+}
+
+// Run runs the experiment like Experiment.Run.
+func (r *Runner[T]) Run(ctx context.Context, opts ...RunOption) (T, error) {
+	return r.e.Run(ctx, opts...)
+}
+
+// DisableBehavior stops running a candidate of the runner until
+// EnableBehavior is called.
+func (r *Runner[T]) DisableBehavior(name string) error {
+	return r.e.DisableBehavior(name)
+}
+
+func (r *Runner[T]) EnableBehavior(name string) error {
+	return r.e.EnableBehavior(name)
+}
+
+// RunOption configures a single run.
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	tags   map[string]any
+	inputs []any
+}
+
+// RunContext adds context to the result of a run, overriding the
+// experiment's Context.
+func RunContext(context map[string]string) RunOption {
+	return func(o *runOptions) {
+		for k, v := range context {
+			Tag(k, v)(o)
+		}
+	}
+}
+
+// Inputs records the inputs of a run in Result.Inputs. The behaviors can
+// read them with RunInputs.
+func Inputs(args ...any) RunOption {
+	return func(o *runOptions) {
+		o.inputs = args
+	}
+}
+
+type inputsKey struct{}
+
+// RunInputs returns the inputs of the run ctx was passed to.
+func RunInputs(ctx context.Context) []any {
+	args, _ := ctx.Value(inputsKey{}).([]any)
+	return args
+}
+
+func newRunOptions(opts []RunOption) runOptions {
+	var o runOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
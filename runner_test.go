@@ -0,0 +1,70 @@
+package scientist
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRunner(t *testing.T) {
+	e := New[int]("runner")
+	basicExperiment(e)
+	e.Context["service"] = "api"
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	e.Publish(func(r *Result[int]) error {
+		if r.Context["service"] != "api" {
+			t.Errorf("expected experiment context, got %v", r.Context)
+		}
+		if len(r.Candidates) != 3 {
+			t.Errorf("expected 3 candidates, got %d", len(r.Candidates))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests[r.Context["request"]]++
+		return nil
+	})
+
+	runner, err := e.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// changes after Build don't reach the runner
+	e.Context["service"] = "changed"
+	e.Behavior("late", func(ctx context.Context) (any, error) {
+		return 1, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := runner.Run(context.Background(), RunContext(map[string]string{"request": strconv.Itoa(i)}))
+			if v != 1 || err != nil {
+				t.Errorf("unexpected control result: %d, %v", v, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(requests) != 50 {
+		t.Errorf("expected a result for each of the 50 requests, got %v", requests)
+	}
+	for request, n := range requests {
+		if n != 1 {
+			t.Errorf("expected one result for request %s, got %d", request, n)
+		}
+	}
+}
+
+func TestRunnerWithoutControl(t *testing.T) {
+	e := New[int]("runner")
+	if _, err := e.Build(); err == nil {
+		t.Error("expected an error building an experiment without a control")
+	}
+}
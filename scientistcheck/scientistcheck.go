@@ -0,0 +1,364 @@
+// Package scientistcheck defines an analyzer that reports common mistakes
+// with experiments:
+//
+//   - experiments that run without a control (Use),
+//   - candidates that call APIs that write, like database/sql Exec,
+//     os.WriteFile or HTTP POST,
+//   - writes to an experiment's Context while it may be shared between
+//     goroutines,
+//   - experiments whose results are never published, and
+//   - calls to Run that drop the returned error.
+package scientistcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const scientistPath = "github.com/freshworks/go-scientist"
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "scientist",
+	Doc:      "report common mistakes with go-scientist experiments",
+	URL:      "https://pkg.go.dev/github.com/freshworks/go-scientist/scientistcheck",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// writes are functions that change state outside the process, keyed by
+// types.Func.FullName.
+var writes = map[string]bool{
+	"(*database/sql.DB).Exec":          true,
+	"(*database/sql.DB).ExecContext":   true,
+	"(*database/sql.Tx).Exec":          true,
+	"(*database/sql.Tx).ExecContext":   true,
+	"(*database/sql.Conn).ExecContext": true,
+	"(*database/sql.Stmt).Exec":        true,
+	"(*database/sql.Stmt).ExecContext": true,
+	"os.WriteFile":                     true,
+	"os.Create":                        true,
+	"os.Remove":                        true,
+	"os.RemoveAll":                     true,
+	"os.Rename":                        true,
+	"os.Mkdir":                         true,
+	"os.MkdirAll":                      true,
+	"os.Truncate":                      true,
+	"net/http.Post":                    true,
+	"net/http.PostForm":                true,
+	"(*net/http.Client).Post":          true,
+	"(*net/http.Client).PostForm":      true,
+}
+
+// writeMethods are HTTP methods that change state.
+var writeMethods = map[string]bool{"POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		if body := n.(*ast.FuncDecl).Body; body != nil {
+			checkExperiments(pass, body)
+		}
+	})
+
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil), (*ast.AssignStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			checkCandidate(pass, n)
+			checkRunError(pass, n, stack)
+		case *ast.AssignStmt:
+			checkContextWrite(pass, n, stack)
+			checkRunAssign(pass, n)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// checkExperiments reports experiments created in body that run without a
+// control or are never published. Experiments that escape, like ones passed
+// to other functions, aren't checked.
+func checkExperiments(pass *analysis.Pass, body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				return true
+			}
+			for i, rhs := range n.Rhs {
+				call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+				if !ok || !isFunc(pass, call, "New") {
+					continue
+				}
+				if id, ok := n.Lhs[i].(*ast.Ident); ok {
+					checkExperiment(pass, body, id, call, false)
+				}
+			}
+
+		case *ast.CallExpr:
+			// scientist.Run(ctx, name, func(e *scientist.Experiment[T]) error { ... })
+			if !isFunc(pass, n, "Run") || len(n.Args) != 3 {
+				return true
+			}
+			setup, ok := n.Args[2].(*ast.FuncLit)
+			if !ok || len(setup.Type.Params.List) != 1 || len(setup.Type.Params.List[0].Names) != 1 {
+				return true
+			}
+			checkExperiment(pass, setup.Body, setup.Type.Params.List[0].Names[0], nil, true)
+		}
+		return true
+	})
+}
+
+func checkExperiment(pass *analysis.Pass, body *ast.BlockStmt, id *ast.Ident, newCall *ast.CallExpr, runs bool) {
+	obj := pass.TypesInfo.ObjectOf(id)
+	if obj == nil || id.Name == "_" {
+		return
+	}
+
+	used := map[string]bool{}
+	if newCall != nil {
+		for _, opt := range newCall.Args[1:] {
+			call, ok := ast.Unparen(opt).(*ast.CallExpr)
+			if !ok {
+				return
+			}
+			fn := callee(pass, call)
+			if fn == nil || !isScientist(fn) || fn.Name() == "WithSetup" || fn.Name() == "Options" {
+				return
+			}
+			used[fn.Name()] = true
+		}
+	}
+
+	uses, selected := 0, 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok && pass.TypesInfo.Uses[x] == obj {
+				selected++
+				used[n.Sel.Name] = true
+			}
+		case *ast.Ident:
+			if pass.TypesInfo.Uses[n] == obj {
+				uses++
+			}
+		}
+		return true
+	})
+	if uses > selected {
+		return
+	}
+
+	if !runs && !used["Run"] && !used["Build"] {
+		return
+	}
+
+	if !used["Use"] && !used["WithControl"] {
+		pass.Reportf(id.Pos(), "experiment %s runs without a control: call Use", id.Name)
+	}
+	if !used["Publish"] && !used["PublishContext"] && !used["WithPublisher"] {
+		pass.Reportf(id.Pos(), "results of experiment %s are never published: call Publish", id.Name)
+	}
+}
+
+// checkCandidate reports candidates that call APIs that write.
+func checkCandidate(pass *analysis.Pass, call *ast.CallExpr) {
+	fn := callee(pass, call)
+	if fn == nil || !isScientist(fn) {
+		return
+	}
+	switch fn.Name() {
+	case "Try", "Behavior", "WithCandidate", "WithBehavior":
+	default:
+		return
+	}
+	if len(call.Args) == 0 {
+		return
+	}
+
+	candidate, ok := call.Args[len(call.Args)-1].(*ast.FuncLit)
+	if !ok {
+		return
+	}
+
+	ast.Inspect(candidate.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if name := writeCall(pass, call); name != "" {
+			pass.Reportf(call.Pos(), "candidate calls %s, which writes: candidates should not have side effects", name)
+		}
+		return true
+	})
+}
+
+// writeCall returns the name of the function call calls if it writes.
+func writeCall(pass *analysis.Pass, call *ast.CallExpr) string {
+	fn := callee(pass, call)
+	if fn == nil {
+		return ""
+	}
+
+	name := fn.FullName()
+	if writes[name] {
+		return name
+	}
+
+	if name == "net/http.NewRequest" || name == "net/http.NewRequestWithContext" {
+		i := 0
+		if name == "net/http.NewRequestWithContext" {
+			i = 1
+		}
+		if len(call.Args) > i {
+			if tv := pass.TypesInfo.Types[call.Args[i]]; tv.Value != nil && tv.Value.Kind() == constant.String {
+				if method := constant.StringVal(tv.Value); writeMethods[strings.ToUpper(method)] {
+					return name + " " + method
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// checkContextWrite reports writes to the Context of an experiment that is
+// a package variable, or that a goroutine shares with its parent.
+func checkContextWrite(pass *analysis.Pass, assign *ast.AssignStmt, stack []ast.Node) {
+	for _, lhs := range assign.Lhs {
+		index, ok := lhs.(*ast.IndexExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := index.X.(*ast.SelectorExpr)
+		if !ok || !isExperimentField(pass, sel, "Context") {
+			continue
+		}
+		id, ok := sel.X.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj := pass.TypesInfo.Uses[id]
+		if obj == nil {
+			continue
+		}
+
+		shared := obj.Parent() == pass.Pkg.Scope()
+		if !shared {
+			if lit := goroutine(stack); lit != nil && (obj.Pos() < lit.Pos() || obj.Pos() > lit.End()) {
+				shared = true
+			}
+		}
+		if shared {
+			pass.Reportf(lhs.Pos(), "Context of experiment %s is written while it may be shared between goroutines: pass per-run context to Run with RunContext or Tag", id.Name)
+		}
+	}
+}
+
+// goroutine returns the function literal started by the innermost go
+// statement in stack.
+func goroutine(stack []ast.Node) *ast.FuncLit {
+	for i := len(stack) - 1; i > 0; i-- {
+		lit, ok := stack[i].(*ast.FuncLit)
+		if !ok {
+			continue
+		}
+		if call, ok := stack[i-1].(*ast.CallExpr); ok && call.Fun == lit && i > 1 {
+			if _, ok := stack[i-2].(*ast.GoStmt); ok {
+				return lit
+			}
+		}
+	}
+	return nil
+}
+
+// checkRunError reports calls to Run whose results are dropped.
+func checkRunError(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) {
+	if !isRun(pass, call) || len(stack) < 2 {
+		return
+	}
+
+	switch stack[len(stack)-2].(type) {
+	case *ast.ExprStmt, *ast.GoStmt, *ast.DeferStmt:
+		pass.Reportf(call.Pos(), "error returned by Run is not checked")
+	}
+}
+
+func checkRunAssign(pass *analysis.Pass, assign *ast.AssignStmt) {
+	if len(assign.Rhs) != 1 || len(assign.Lhs) != 2 {
+		return
+	}
+	call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+	if !ok || !isRun(pass, call) {
+		return
+	}
+	if id, ok := assign.Lhs[1].(*ast.Ident); ok && id.Name == "_" {
+		pass.Reportf(id.Pos(), "error returned by Run is not checked")
+	}
+}
+
+// isRun reports whether call is scientist.Run or the Run method of an
+// Experiment or Runner.
+func isRun(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn := callee(pass, call)
+	if fn == nil || !isScientist(fn) || fn.Name() != "Run" {
+		return false
+	}
+
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return true
+	}
+	name := typeName(recv.Type())
+	return name == "Experiment" || name == "Runner"
+}
+
+// isFunc reports whether call calls the package level function name of
+// scientist.
+func isFunc(pass *analysis.Pass, call *ast.CallExpr, name string) bool {
+	fn := callee(pass, call)
+	return fn != nil && isScientist(fn) && fn.Name() == name && fn.Type().(*types.Signature).Recv() == nil
+}
+
+func isExperimentField(pass *analysis.Pass, sel *ast.SelectorExpr, name string) bool {
+	s, ok := pass.TypesInfo.Selections[sel]
+	if !ok || s.Kind() != types.FieldVal || s.Obj().Name() != name {
+		return false
+	}
+	return s.Obj().Pkg() != nil && s.Obj().Pkg().Path() == scientistPath && typeName(s.Recv()) == "Experiment"
+}
+
+func callee(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	fn, _ := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if fn != nil {
+		fn = fn.Origin()
+	}
+	return fn
+}
+
+func isScientist(obj types.Object) bool {
+	return obj.Pkg() != nil && obj.Pkg().Path() == scientistPath
+}
+
+// typeName returns the name of a possibly pointer, possibly generic named
+// type.
+func typeName(t types.Type) string {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Origin().Obj().Name()
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package a
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"os"
+
+	"github.com/freshworks/go-scientist"
+)
+
+func publish(r *scientist.Result[int]) error { return nil }
+
+func good(ctx context.Context) (int, error) {
+	e := scientist.New[int]("good")
+	e.Use(func(ctx context.Context) (int, error) { return 1, nil })
+	e.Try(func(ctx context.Context) (any, error) { return 1, nil })
+	e.Publish(publish)
+	return e.Run(ctx)
+}
+
+func goodOptions(ctx context.Context) (int, error) {
+	e := scientist.New[int]("good",
+		scientist.WithControl(func(ctx context.Context) (int, error) { return 1, nil }),
+		scientist.WithPublisher(publish),
+	)
+	return e.Run(ctx)
+}
+
+func noControl(ctx context.Context) (int, error) {
+	e := scientist.New[int]("no-control") // want `experiment e runs without a control: call Use`
+	e.Try(func(ctx context.Context) (any, error) { return 1, nil })
+	e.Publish(publish)
+	return e.Run(ctx)
+}
+
+func noPublish(ctx context.Context) (int, error) {
+	e := scientist.New[int]("no-publish") // want `results of experiment e are never published: call Publish`
+	e.Use(func(ctx context.Context) (int, error) { return 1, nil })
+	return e.Run(ctx)
+}
+
+func noPublishRun(ctx context.Context) (int, error) {
+	return scientist.Run(ctx, "no-publish", func(e *scientist.Experiment[int]) error { // want `results of experiment e are never published: call Publish`
+		e.Use(func(ctx context.Context) (int, error) { return 1, nil })
+		return nil
+	})
+}
+
+func escapes(ctx context.Context) (int, error) {
+	e := scientist.New[int]("escapes")
+	setup(e)
+	return e.Run(ctx)
+}
+
+func bundled(ctx context.Context, defaults scientist.Option) (int, error) {
+	e := scientist.New[int]("bundled", defaults)
+	return e.Run(ctx)
+}
+
+func setup(e *scientist.Experiment[int]) {}
+
+func writes(ctx context.Context, db *sql.DB) {
+	e := scientist.New[int]("writes")
+	e.Use(func(ctx context.Context) (int, error) { return 1, nil })
+	e.Publish(publish)
+	e.Try(func(ctx context.Context) (any, error) {
+		db.Exec("DELETE FROM widgets")                             // want `candidate calls \(\*database/sql.DB\).Exec, which writes`
+		os.WriteFile("widgets", nil, 0o644)                        // want `candidate calls os.WriteFile, which writes`
+		http.Post("http://example.com", "", nil)                   // want `candidate calls net/http.Post, which writes`
+		http.NewRequest(http.MethodPut, "http://example.com", nil) // want `candidate calls net/http.NewRequest PUT, which writes`
+		http.NewRequest("GET", "http://example.com", nil)
+		return db.Query("SELECT 1")
+	})
+	e.Behavior("other", func(ctx context.Context) (any, error) {
+		return nil, os.Remove("widgets") // want `candidate calls os.Remove, which writes`
+	})
+	if _, err := e.Run(ctx); err != nil {
+		panic(err)
+	}
+}
+
+var shared = scientist.New[int]("shared")
+
+func handler(user string) {
+	shared.Context["user"] = user // want `Context of experiment shared is written while it may be shared between goroutines`
+}
+
+func goroutines(ctx context.Context, e *scientist.Experiment[int]) {
+	for _, user := range []string{"a", "b"} {
+		go func() {
+			e.Context["user"] = user // want `Context of experiment e is written while it may be shared between goroutines`
+			v, err := e.Run(ctx, scientist.RunContext(map[string]string{"user": user}))
+			_, _ = v, err
+		}()
+	}
+
+	go func() {
+		local := scientist.New[int]("local")
+		local.Context["user"] = "a"
+	}()
+}
+
+func dropped(ctx context.Context, e *scientist.Experiment[int], r *scientist.Runner[int]) {
+	e.Run(ctx)         // want `error returned by Run is not checked`
+	go r.Run(ctx)      // want `error returned by Run is not checked`
+	v, _ := e.Run(ctx) // want `error returned by Run is not checked`
+	_ = v
+	scientist.Run(ctx, "dropped", func(e *scientist.Experiment[int]) error { // want `error returned by Run is not checked`
+		e.Use(func(ctx context.Context) (int, error) { return 1, nil })
+		e.Publish(publish)
+		return nil
+	})
+}
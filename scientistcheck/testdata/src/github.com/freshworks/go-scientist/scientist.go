@@ -0,0 +1,44 @@
+// Package scientist is a stub of the parts of go-scientist the analyzer
+// looks at.
+package scientist
+
+import "context"
+
+type Experiment[T any] struct {
+	Name    string
+	Context map[string]string
+}
+
+type Runner[T any] struct{}
+
+type Result[T any] struct{}
+
+type Option func(any)
+
+type RunOption func(any)
+
+func New[T any](name string, opts ...Option) *Experiment[T] { return &Experiment[T]{} }
+
+func Run[T any](ctx context.Context, name string, setup func(*Experiment[T]) error) (T, error) {
+	return *new(T), nil
+}
+
+func (e *Experiment[T]) Use(fn func(ctx context.Context) (T, error))                     {}
+func (e *Experiment[T]) Try(fn func(ctx context.Context) (any, error))                   {}
+func (e *Experiment[T]) Behavior(name string, fn func(ctx context.Context) (any, error)) {}
+func (e *Experiment[T]) Publish(fn func(*Result[T]) error)                               {}
+func (e *Experiment[T]) Build() (*Runner[T], error)                                      { return &Runner[T]{}, nil }
+
+func (e *Experiment[T]) Run(ctx context.Context, opts ...RunOption) (T, error) {
+	return *new(T), nil
+}
+
+func (r *Runner[T]) Run(ctx context.Context, opts ...RunOption) (T, error) {
+	return *new(T), nil
+}
+
+func WithControl[T any](fn func(ctx context.Context) (T, error)) Option { return nil }
+func WithCandidate(fn func(ctx context.Context) (any, error)) Option    { return nil }
+func WithPublisher[T any](fn func(*Result[T]) error) Option             { return nil }
+func WithSetup[T any](fn func(*Experiment[T])) Option                   { return nil }
+func RunContext(context map[string]string) RunOption                    { return nil }
@@ -0,0 +1,177 @@
+package scientisttest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxDepth stops Diff from following cyclic pointers forever.
+const maxDepth = 32
+
+// Diff returns the differences between a control's and a candidate's values,
+// each with the path to the value that differs, or "" when they are deeply
+// equal:
+//
+//	.Users[1].Name:
+//	  - "octocat"
+//	  + "monalisa"
+func Diff(control, candidate any) string {
+	var d differ
+	d.diff("", reflect.ValueOf(control), reflect.ValueOf(candidate), 0)
+	return d.b.String()
+}
+
+type differ struct {
+	b strings.Builder
+}
+
+func (d *differ) report(path string, control, candidate string) {
+	if path == "" {
+		path = "value"
+	}
+	fmt.Fprintf(&d.b, "%s:\n  - %s\n  + %s\n", path, control, candidate)
+}
+
+func (d *differ) diff(path string, a, b reflect.Value, depth int) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.report(path, format(a), format(b))
+		}
+		return
+	}
+
+	if a.Type() != b.Type() {
+		d.report(path, typed(a), typed(b))
+		return
+	}
+
+	if depth > maxDepth {
+		if !equal(a, b) {
+			d.report(path, format(a), format(b))
+		}
+		return
+	}
+
+	if eq, ok := equalMethod(a, b); ok {
+		if !eq {
+			d.report(path, format(a), format(b))
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.report(path, format(a), format(b))
+			}
+			return
+		}
+		if a.Kind() == reflect.Pointer && a.Pointer() == b.Pointer() {
+			return
+		}
+		d.diff(path, a.Elem(), b.Elem(), depth+1)
+
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			d.diff(path+"."+a.Type().Field(i).Name, a.Field(i), b.Field(i), depth+1)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() && (a.Len() > 0 || b.Len() > 0) {
+			d.report(path, format(a), format(b))
+			return
+		}
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			elem := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				d.report(elem, "<missing>", format(b.Index(i)))
+			case i >= b.Len():
+				d.report(elem, format(a.Index(i)), "<missing>")
+			default:
+				d.diff(elem, a.Index(i), b.Index(i), depth+1)
+			}
+		}
+
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[format(k)] = k
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, name := range sorted {
+			k := keys[name]
+			elem := fmt.Sprintf("%s[%s]", path, name)
+			av, bv := a.MapIndex(k), b.MapIndex(k)
+			switch {
+			case !av.IsValid():
+				d.report(elem, "<missing>", format(bv))
+			case !bv.IsValid():
+				d.report(elem, format(av), "<missing>")
+			default:
+				d.diff(elem, av, bv, depth+1)
+			}
+		}
+
+	default:
+		if !equal(a, b) {
+			d.report(path, format(a), format(b))
+		}
+	}
+}
+
+// equalMethod compares values with an Equal method, like time.Time's.
+func equalMethod(a, b reflect.Value) (equal, ok bool) {
+	if !a.CanInterface() {
+		return false, false
+	}
+	m := a.MethodByName("Equal")
+	if !m.IsValid() || m.Type().NumIn() != 1 || m.Type().In(0) != a.Type() || m.Type().NumOut() != 1 || m.Type().Out(0).Kind() != reflect.Bool {
+		return false, false
+	}
+	return m.Call([]reflect.Value{b})[0].Bool(), true
+}
+
+func equal(a, b reflect.Value) bool {
+	if a.CanInterface() && b.CanInterface() {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	return format(a) == format(b)
+}
+
+// format formats a value, including the values of unexported fields.
+func format(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%#v", v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return fmt.Sprint(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Float())
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	default:
+		return fmt.Sprintf("<unexported %s>", v.Type())
+	}
+}
+
+func typed(v reflect.Value) string {
+	return fmt.Sprintf("%s (%s)", format(v), v.Type())
+}
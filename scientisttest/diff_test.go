@@ -0,0 +1,55 @@
+package scientisttest
+
+import (
+	"testing"
+	"time"
+)
+
+type account struct {
+	ID      int
+	Owner   *owner
+	Tags    map[string]int
+	Created time.Time
+	secret  string
+}
+
+type owner struct {
+	Name string
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	control := account{ID: 1, Owner: &owner{"hubot"}, Tags: map[string]int{"a": 1, "b": 2}, Created: now, secret: "x"}
+
+	if diff := Diff(control, control); diff != "" {
+		t.Errorf("expected no diff for equal values, got:\n%s", diff)
+	}
+
+	candidate := account{ID: 1, Owner: &owner{"octocat"}, Tags: map[string]int{"a": 1, "c": 3}, Created: now.In(time.UTC), secret: "y"}
+	expected := `.Owner.Name:
+  - "hubot"
+  + "octocat"
+.Tags["b"]:
+  - 2
+  + <missing>
+.Tags["c"]:
+  - <missing>
+  + 3
+.secret:
+  - "x"
+  + "y"
+`
+	if diff := Diff(control, candidate); diff != expected {
+		t.Errorf("unexpected diff:\n%s\nexpected:\n%s", diff, expected)
+	}
+
+	if diff := Diff([]int{1, 2}, []int{1}); diff != "[1]:\n  - 2\n  + <missing>\n" {
+		t.Errorf("unexpected slice diff:\n%s", diff)
+	}
+	if diff := Diff(1, "1"); diff != "value:\n  - 1 (int)\n  + \"1\" (string)\n" {
+		t.Errorf("unexpected type diff:\n%s", diff)
+	}
+	if diff := Diff(nil, 1); diff != "value:\n  - nil\n  + 1\n" {
+		t.Errorf("unexpected nil diff:\n%s", diff)
+	}
+}
@@ -0,0 +1,65 @@
+package scientisttest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/freshworks/go-scientist"
+)
+
+// Fuzz runs an experiment as a differential fuzz test: for every input, it
+// builds the experiment with build, runs the control and every candidate
+// synchronously, and fails on any mismatch that isn't ignored, printing the
+// input and the diff.
+//
+//	func FuzzParse(f *testing.F) {
+//		f.Add("1.2.3")
+//		scientisttest.Fuzz(f, func(s string) *scientist.Experiment[Version] {
+//			e := scientist.New[Version]("parse")
+//			e.Use(func(ctx context.Context) (Version, error) { return parse(s) })
+//			e.Try(func(ctx context.Context) (any, error) { return parseFast(s) })
+//			return e
+//		})
+//	}
+//
+// The input must be one of the types supported by testing.F, like string,
+// []byte or int. Decode a []byte to fuzz more complex inputs. The experiment
+// runs regardless of RunIf, Enable, configuration files and flags, and its
+// publisher is replaced, so results aren't published while fuzzing.
+func Fuzz[A, T any](f *testing.F, build func(input A) *scientist.Experiment[T]) {
+	f.Helper()
+	f.Fuzz(func(t *testing.T, input A) {
+		t.Helper()
+		Check(t, input, build)
+	})
+}
+
+// Check runs the experiment built from input like Fuzz does, failing the
+// test on any mismatch. TestCase emits tests calling it.
+func Check[A, T any](t testing.TB, input A, build func(input A) *scientist.Experiment[T]) {
+	t.Helper()
+	Force(t)
+
+	rec := NewRecorder[T]()
+	e := build(input)
+	e.Publish(rec.Publish)
+	// the control's error is part of the behavior under test
+	_, _ = e.Run(context.Background())
+
+	r := rec.Last()
+	if r == nil {
+		t.Fatalf("experiment %s ran no candidates for input %#v", e.Name, input)
+	}
+	if !r.IsMismatched() {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "experiment %s: %d candidates mismatched for input %#v", e.Name, len(r.Mismatched), input)
+	for _, o := range r.Mismatched {
+		writeMismatch(&b, r, o)
+	}
+	t.Fatal(b.String())
+}
@@ -0,0 +1,67 @@
+package scientisttest
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/freshworks/go-scientist"
+)
+
+func atoi(candidate func(s string) (int, error)) func(s string) *scientist.Experiment[int] {
+	return func(s string) *scientist.Experiment[int] {
+		e := scientist.New[int]("atoi")
+		e.Use(func(ctx context.Context) (int, error) {
+			return strconv.Atoi(s)
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			return candidate(s)
+		})
+		return e
+	}
+}
+
+// digits parses unsigned numbers itself, leaving the rest to strconv.
+func digits(s string) (int, error) {
+	n := 0
+	for _, c := range s {
+		if c < '0' || c > '9' || n > 1e17 {
+			return strconv.Atoi(s)
+		}
+		n = n*10 + int(c-'0')
+	}
+	if s == "" {
+		return strconv.Atoi(s)
+	}
+	return n, nil
+}
+
+func FuzzAtoi(f *testing.F) {
+	for _, seed := range []string{"0", "42", "", "-1", "x"} {
+		f.Add(seed)
+	}
+	Fuzz(f, atoi(digits))
+}
+
+func TestFuzzMismatch(t *testing.T) {
+	unsigned := atoi(func(s string) (int, error) {
+		return digits(strings.TrimPrefix(s, "-"))
+	})
+
+	msg := failure(func(ft testing.TB) { Check(ft, "-7", unsigned) })
+	for _, expected := range []string{
+		`experiment atoi: 1 candidates mismatched for input "-7"`,
+		"candidate candidate:",
+		"- -7",
+		"+ 7",
+	} {
+		if !strings.Contains(msg, expected) {
+			t.Errorf("expected %q in failure:\n%s", expected, msg)
+		}
+	}
+
+	if msg := failure(func(ft testing.TB) { Check(ft, "7", unsigned) }); msg != "" {
+		t.Errorf("unexpected failure: %s", msg)
+	}
+}
@@ -0,0 +1,145 @@
+package scientisttest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/freshworks/go-scientist"
+)
+
+var update = flag.Bool("scientist.update", false, "update the golden files of scientisttest.Golden with the control's results")
+
+// Golden runs a subtest for every golden file in dir, checking the
+// experiment built by build from the file's input. A golden file is a
+// scientist.CaptureRecord, as JSON, with a single input: a record of a
+// capture corpus can be copied to a file as is.
+//
+// The subtest fails if the control's value or error differs from the
+// recorded ones, printing the diff, or if any candidate mismatches the
+// control. Run the tests with -scientist.update to write the control's
+// results to the golden files instead, when it changed on purpose.
+//
+// Experiments run like in Check: synchronously, regardless of RunIf, and
+// aren't published.
+func Golden[A, T any](t *testing.T, dir string, build func(input A) *scientist.Experiment[T]) {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no golden files in %s", dir)
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			golden(t, path, build)
+		})
+	}
+}
+
+func golden[A, T any](t testing.TB, path string, build func(input A) *scientist.Experiment[T]) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec scientist.CaptureRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if len(rec.Inputs) != 1 {
+		t.Fatalf("%s: expected 1 input, got %d", path, len(rec.Inputs))
+	}
+	var input A
+	if err := json.Unmarshal(rec.Inputs[0], &input); err != nil {
+		t.Fatalf("%s: input: %v", path, err)
+	}
+
+	Force(t)
+	recorder := NewRecorder[T]()
+	e := build(input)
+	e.Publish(recorder.Publish)
+	value, err := e.Run(context.Background())
+
+	var expected, actual T
+	if len(rec.Value) > 0 {
+		if err := json.Unmarshal(rec.Value, &expected); err != nil {
+			t.Fatalf("%s: value: %v", path, err)
+		}
+	}
+	var raw json.RawMessage
+	if err == nil {
+		// compare the values as the golden file stores them
+		raw = roundTripJSON(t, value, &actual)
+	}
+
+	var errString string
+	if err != nil {
+		errString = err.Error()
+	}
+
+	if errString != rec.Error || Diff(expected, actual) != "" {
+		if *update {
+			rec.Value, rec.Error = raw, errString
+			writeGolden(t, path, rec)
+		} else {
+			t.Errorf("%s: the control's result changed, run with -scientist.update if it's intended:\n%s", path, controlDiff(rec.Error, errString, expected, actual))
+		}
+	}
+
+	r := recorder.Last()
+	if r == nil {
+		t.Fatalf("experiment %s ran no candidates", e.Name)
+	}
+	if r.IsMismatched() {
+		var b strings.Builder
+		fmt.Fprintf(&b, "experiment %s: %d candidates mismatched", e.Name, len(r.Mismatched))
+		for _, o := range r.Mismatched {
+			writeMismatch(&b, r, o)
+		}
+		t.Error(b.String())
+	}
+}
+
+func roundTripJSON[T any](t testing.TB, value T, decoded *T) json.RawMessage {
+	t.Helper()
+
+	raw, err := json.Marshal(value)
+	if err == nil {
+		err = json.Unmarshal(raw, decoded)
+	}
+	if err != nil {
+		t.Fatalf("control value: %v", err)
+	}
+	return raw
+}
+
+func controlDiff[T any](expectedErr, actualErr string, expected, actual T) string {
+	if expectedErr != actualErr {
+		return fmt.Sprintf("error:\n  - %q\n  + %q\n", expectedErr, actualErr)
+	}
+	return Diff(expected, actual)
+}
+
+func writeGolden(t testing.TB, path string, rec scientist.CaptureRecord) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("updated %s", path)
+}
@@ -0,0 +1,58 @@
+package scientisttest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	Golden(t, "testdata/golden", atoi(digits))
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "number.json")
+	if err := os.WriteFile(path, []byte(`{"experiment":"atoi","inputs":["42"],"value":41}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := failure(func(ft testing.TB) { golden(ft, path, atoi(digits)) })
+	if !strings.Contains(msg, "the control's result changed") || !strings.Contains(msg, "value:\n  - 41\n  + 42\n") {
+		t.Errorf("unexpected failure: %q", msg)
+	}
+
+	*update = true
+	defer func() { *update = false }()
+	if msg := failure(func(ft testing.TB) { golden(ft, path, atoi(digits)) }); msg != "" {
+		t.Errorf("unexpected failure while updating: %q", msg)
+	}
+	*update = false
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"value": 42`) {
+		t.Errorf("expected the golden file to be updated, got:\n%s", data)
+	}
+	if msg := failure(func(ft testing.TB) { golden(ft, path, atoi(digits)) }); msg != "" {
+		t.Errorf("unexpected failure after updating: %q", msg)
+	}
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	unsigned := atoi(func(s string) (int, error) {
+		return digits(strings.TrimPrefix(s, "-"))
+	})
+
+	path := filepath.Join(t.TempDir(), "negative.json")
+	if err := os.WriteFile(path, []byte(`{"experiment":"atoi","inputs":["-7"],"value":-7}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := failure(func(ft testing.TB) { golden(ft, path, unsigned) })
+	if strings.Contains(msg, "the control's result changed") || !strings.Contains(msg, "experiment atoi: 1 candidates mismatched") {
+		t.Errorf("unexpected failure: %q", msg)
+	}
+}
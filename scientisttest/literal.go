@@ -0,0 +1,264 @@
+package scientisttest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freshworks/go-scientist"
+)
+
+// TestCase returns a Go test named name checking the experiment built by
+// build for input, ready to paste in a _test.go file of build's package. Use
+// it to keep a minimized input as a regression test:
+//
+//	func TestParseNegative(t *testing.T) {
+//		scientisttest.Check(t, "-7", newParseExperiment)
+//	}
+//
+// Types of build's package are written unqualified, others with their
+// package's name, which may need importing. Unexported fields of other
+// packages' types are left out. If build isn't a top-level function, the
+// test calls a function named build instead.
+func TestCase[A, T any](name string, input A, build func(input A) *scientist.Experiment[T]) string {
+	pkg, fn := funcName(build)
+	l := literal{pkg: pkg}
+	if fn == "" {
+		fn = "build"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", name)
+	fmt.Fprintf(&b, "\tscientisttest.Check(t, %s, %s)\n", l.indent(l.value(reflect.ValueOf(input), false, 0), "\t"), fn)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// funcName returns the package path and name of a top-level function, or
+// only its package path for closures and methods.
+func funcName(fn any) (pkg, name string) {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "", ""
+	}
+	full := f.Name()
+
+	// the package path may contain dots in its last element
+	slash := strings.LastIndex(full, "/")
+	dot := strings.Index(full[slash+1:], ".")
+	if dot < 0 {
+		return "", ""
+	}
+	pkg, name = full[:slash+1+dot], full[slash+1+dot+1:]
+	if strings.ContainsAny(name, ".[") {
+		return pkg, ""
+	}
+	return pkg, name
+}
+
+// literal writes values as Go expressions.
+type literal struct {
+	// pkg is the path of the package the expressions are written in.
+	pkg string
+}
+
+// value writes v. typed is true where v's type can't be inferred, like for
+// the dynamic values of interfaces, so untyped constants need a conversion.
+func (l literal) value(v reflect.Value, typed bool, depth int) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	if depth > maxDepth {
+		return fmt.Sprintf("%s{} /* too deep */", l.typeName(v.Type()))
+	}
+	t := v.Type()
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return l.constant(t, strconv.FormatBool(v.Bool()), typed, reflect.Bool)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return l.constant(t, strconv.FormatInt(v.Int(), 10), typed, reflect.Int)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return l.constant(t, strconv.FormatUint(v.Uint(), 10), typed, reflect.Invalid)
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return l.convert(t, "math.NaN()", t.Kind() == reflect.Float64 && t.Name() == "float64")
+		case math.IsInf(f, 0):
+			return l.convert(t, fmt.Sprintf("math.Inf(%d)", int(math.Copysign(1, f))), t.Kind() == reflect.Float64 && t.Name() == "float64")
+		}
+		s := strconv.FormatFloat(f, 'g', -1, t.Bits())
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return l.constant(t, s, typed, reflect.Float64)
+
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return l.convert(t, fmt.Sprintf("complex(%g, %g)", real(c), imag(c)), t.Kind() == reflect.Complex128 && t.Name() == "complex128")
+
+	case reflect.String:
+		return l.constant(t, strconv.Quote(v.String()), typed, reflect.String)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return l.null(t, typed)
+		}
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = l.value(v.Index(i), false, depth+1)
+		}
+		return l.composite(t, elems)
+
+	case reflect.Array:
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = l.value(v.Index(i), false, depth+1)
+		}
+		return l.composite(t, elems)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return l.null(t, typed)
+		}
+		keys := v.MapKeys()
+		elems := make([]string, len(keys))
+		for i, k := range keys {
+			elems[i] = l.value(k, false, depth+1) + ": " + l.value(v.MapIndex(k), false, depth+1)
+		}
+		sort.Strings(elems)
+		return l.composite(t, elems)
+
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) && v.CanInterface() {
+			tm := v.Interface().(time.Time).UTC()
+			return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, time.UTC)", tm.Year(), tm.Month(), tm.Day(), tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond())
+		}
+		var fields []string
+		omitted := false
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			if v.Field(i).IsZero() {
+				continue
+			}
+			if !f.IsExported() && t.PkgPath() != l.pkg {
+				omitted = true
+				continue
+			}
+			fields = append(fields, f.Name+": "+l.value(v.Field(i), false, depth+1))
+		}
+		if omitted {
+			fields = append(fields, "// unexported fields left out")
+		}
+		return l.composite(t, fields)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return l.null(t, typed)
+		}
+		switch t.Elem().Kind() {
+		case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+			if elem := l.value(v.Elem(), false, depth+1); strings.HasSuffix(elem, "}") {
+				return "&" + elem
+			}
+		}
+		return fmt.Sprintf("func() %s { v := %s; return &v }()", l.typeName(t), l.value(v.Elem(), true, depth+1))
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return l.value(v.Elem(), true, depth+1)
+
+	default:
+		return l.null(t, typed)
+	}
+}
+
+// constant writes an untyped constant, converting it if its type can't be
+// inferred and isn't the constant's default type.
+func (l literal) constant(t reflect.Type, s string, typed bool, kind reflect.Kind) string {
+	if !typed {
+		return s
+	}
+	return l.convert(t, s, t.Kind() == kind && t.PkgPath() == "" && kind != reflect.Invalid)
+}
+
+func (l literal) convert(t reflect.Type, s string, inferred bool) string {
+	if inferred {
+		return s
+	}
+	return fmt.Sprintf("%s(%s)", l.typeName(t), s)
+}
+
+func (l literal) null(t reflect.Type, typed bool) string {
+	if !typed {
+		return "nil"
+	}
+	return fmt.Sprintf("(%s)(nil)", l.typeName(t))
+}
+
+// composite writes a composite literal, on several lines if it's long.
+func (l literal) composite(t reflect.Type, elems []string) string {
+	name := l.typeName(t)
+	if len(elems) == 0 {
+		return name + "{}"
+	}
+
+	oneLine := name + "{" + strings.Join(elems, ", ") + "}"
+	if len(oneLine) <= 80 && !strings.Contains(oneLine, "\n") && !strings.Contains(oneLine, "//") {
+		return oneLine
+	}
+
+	var b strings.Builder
+	b.WriteString(name + "{\n")
+	for _, e := range elems {
+		b.WriteString("\t" + l.indent(e, "\t"))
+		if !strings.HasPrefix(e, "//") {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// indent indents every line of s but the first.
+func (l literal) indent(s, prefix string) string {
+	return strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
+
+func (l literal) typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" || t.PkgPath() == l.pkg {
+			return t.Name()
+		}
+		// String qualifies the name with the package's name
+		return t.String()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + l.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + l.typeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), l.typeName(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", l.typeName(t.Key()), l.typeName(t.Elem()))
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	}
+	return t.String()
+}
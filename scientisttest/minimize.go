@@ -0,0 +1,273 @@
+package scientisttest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/freshworks/go-scientist"
+)
+
+// maxRuns bounds the number of experiments Minimize runs.
+const maxRuns = 10000
+
+// Minimize shrinks an input for which the experiment built by build
+// mismatches, and returns the smallest input it found that still mismatches.
+// It removes elements of slices, strings and maps, zeroes struct fields,
+// pointers and interfaces, and moves numbers towards zero, keeping every
+// change after which the experiment still mismatches.
+//
+// Unexported struct fields are left as they are. Experiments run like in
+// Check, synchronously and regardless of RunIf, and aren't published. It
+// returns an error if the experiment doesn't mismatch for input.
+func Minimize[A, T any](input A, build func(input A) *scientist.Experiment[T]) (A, error) {
+	defer scientist.Override(func(o *scientist.Overrides) {
+		o.Synchronous = true
+		o.Enabled = true
+	})()
+
+	if !mismatches(input, build) {
+		return input, fmt.Errorf("experiment doesn't mismatch for input %#v", input)
+	}
+
+	current := reflect.ValueOf(input)
+	if !current.IsValid() {
+		// a nil interface, which can't get any smaller
+		return input, nil
+	}
+	for runs := 0; runs < maxRuns; {
+		shrunk := false
+		shrinks(current, 0, func(v reflect.Value) bool {
+			runs++
+			if runs > maxRuns {
+				return false
+			}
+			if mismatches(v.Interface().(A), build) {
+				current, shrunk = v, true
+				return false
+			}
+			return true
+		})
+		if !shrunk {
+			break
+		}
+	}
+	return current.Interface().(A), nil
+}
+
+// mismatches runs the experiment built from input, reporting whether any
+// candidate mismatched. Panics of build count as not mismatching, so they
+// don't stop Minimize.
+func mismatches[A, T any](input A, build func(input A) *scientist.Experiment[T]) (mismatched bool) {
+	defer func() {
+		if recover() != nil {
+			mismatched = false
+		}
+	}()
+
+	rec := NewRecorder[T]()
+	e := build(input)
+	e.Publish(rec.Publish)
+	_, _ = e.Run(context.Background())
+
+	r := rec.Last()
+	return r != nil && r.IsMismatched()
+}
+
+// shrinks calls yield with values smaller than v, largest reductions first,
+// until yield returns false. It never modifies v: every value passed to yield
+// is a copy, sharing the parts of v it didn't change. It returns false if
+// yield stopped it.
+func shrinks(v reflect.Value, depth int, yield func(v reflect.Value) bool) bool {
+	if !v.IsValid() || depth > maxDepth {
+		return true
+	}
+	t := v.Type()
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return true
+		}
+		n := v.Len()
+		if !remove(n, func(start, end int) bool {
+			s := reflect.MakeSlice(t, 0, n-(end-start))
+			s = reflect.AppendSlice(s, v.Slice(0, start))
+			return yield(reflect.AppendSlice(s, v.Slice(end, n)))
+		}) {
+			return false
+		}
+		for i := 0; i < n; i++ {
+			if !shrinks(v.Index(i), depth+1, func(elem reflect.Value) bool {
+				s := reflect.MakeSlice(t, n, n)
+				reflect.Copy(s, v)
+				s.Index(i).Set(elem)
+				return yield(s)
+			}) {
+				return false
+			}
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !shrinks(v.Index(i), depth+1, func(elem reflect.Value) bool {
+				a := reflect.New(t).Elem()
+				a.Set(v)
+				a.Index(i).Set(elem)
+				return yield(a)
+			}) {
+				return false
+			}
+		}
+
+	case reflect.String:
+		runes := []rune(v.String())
+		return remove(len(runes), func(start, end int) bool {
+			s := string(runes[:start]) + string(runes[end:])
+			return yield(reflect.ValueOf(s).Convert(t))
+		})
+
+	case reflect.Map:
+		if v.IsNil() {
+			return true
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return format(keys[i]) < format(keys[j])
+		})
+		copyMap := func(skip func(i int) bool) reflect.Value {
+			m := reflect.MakeMapWithSize(t, len(keys))
+			for i, k := range keys {
+				if !skip(i) {
+					m.SetMapIndex(k, v.MapIndex(k))
+				}
+			}
+			return m
+		}
+		if !remove(len(keys), func(start, end int) bool {
+			return yield(copyMap(func(i int) bool { return i >= start && i < end }))
+		}) {
+			return false
+		}
+		for _, k := range keys {
+			if !shrinks(v.MapIndex(k), depth+1, func(elem reflect.Value) bool {
+				m := copyMap(func(int) bool { return false })
+				m.SetMapIndex(k, elem)
+				return yield(m)
+			}) {
+				return false
+			}
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			field := func(f reflect.Value) reflect.Value {
+				s := reflect.New(t).Elem()
+				s.Set(v)
+				s.Field(i).Set(f)
+				return s
+			}
+			if !v.Field(i).IsZero() && !yield(field(reflect.Zero(t.Field(i).Type))) {
+				return false
+			}
+			if !shrinks(v.Field(i), depth+1, func(f reflect.Value) bool {
+				return yield(field(f))
+			}) {
+				return false
+			}
+		}
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return true
+		}
+		if !yield(reflect.Zero(t)) {
+			return false
+		}
+		return shrinks(v.Elem(), depth+1, func(elem reflect.Value) bool {
+			p := reflect.New(t.Elem())
+			p.Elem().Set(elem)
+			return yield(p)
+		})
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+		if !yield(reflect.Zero(t)) {
+			return false
+		}
+		return shrinks(v.Elem(), depth+1, func(elem reflect.Value) bool {
+			i := reflect.New(t).Elem()
+			i.Set(elem)
+			return yield(i)
+		})
+
+	case reflect.Bool:
+		if v.Bool() {
+			return yield(reflect.Zero(t))
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for _, n := range towardsZero(v.Int()) {
+			i := reflect.New(t).Elem()
+			i.SetInt(n)
+			if !yield(i) {
+				return false
+			}
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := v.Uint(); n != 0 {
+			for _, n := range []uint64{0, n / 2, n - 1} {
+				u := reflect.New(t).Elem()
+				u.SetUint(n)
+				if !yield(u) {
+					return false
+				}
+			}
+		}
+
+	case reflect.Float32, reflect.Float64:
+		for _, n := range []float64{0, math.Trunc(v.Float())} {
+			if n == v.Float() {
+				continue
+			}
+			f := reflect.New(t).Elem()
+			f.SetFloat(n)
+			if !yield(f) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// remove calls try with the bounds of chunks to remove from n elements: all
+// of them, then halves, quarters and so on down to single elements.
+func remove(n int, try func(start, end int) bool) bool {
+	for size := n; size > 0; size /= 2 {
+		for start := 0; start < n; start += size {
+			if !try(start, min(start+size, n)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func towardsZero(n int64) []int64 {
+	switch {
+	case n == 0:
+		return nil
+	case n > 0:
+		return []int64{0, n / 2, n - 1}
+	default:
+		return []int64{0, n / 2, n + 1}
+	}
+}
@@ -0,0 +1,136 @@
+package scientisttest
+
+import (
+	"context"
+	"go/parser"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freshworks/go-scientist"
+)
+
+type order struct {
+	ID    string
+	Items []item
+	Meta  map[string]any
+	Note  *string
+}
+
+type item struct {
+	SKU string
+	Qty int
+}
+
+// orderExperiment's candidate drops items of more than 100.
+func orderExperiment(o order) *scientist.Experiment[int] {
+	e := scientist.New[int]("order")
+	e.Use(func(ctx context.Context) (int, error) {
+		total := 0
+		for _, i := range o.Items {
+			total += i.Qty
+		}
+		return total, nil
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		total := 0
+		for _, i := range o.Items {
+			if i.Qty <= 100 {
+				total += i.Qty
+			}
+		}
+		return total, nil
+	})
+	return e
+}
+
+func TestMinimize(t *testing.T) {
+	note := "rush"
+	input := order{
+		ID:    "o-1",
+		Items: []item{{"a", 1}, {"b", 2}, {"c", 500}, {"d", 4}},
+		Meta:  map[string]any{"source": "web", "retries": 3},
+		Note:  &note,
+	}
+
+	min, err := Minimize(input, orderExperiment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := order{Items: []item{{Qty: 101}}}
+	if !reflect.DeepEqual(min, expected) {
+		t.Errorf("expected %#v, got %#v", expected, min)
+	}
+	if input.ID != "o-1" || len(input.Items) != 4 || input.Items[2].Qty != 500 || len(input.Meta) != 2 {
+		t.Errorf("the input was modified: %#v", input)
+	}
+
+	if _, err := Minimize(order{}, orderExperiment); err == nil {
+		t.Errorf("expected an error for an input that doesn't mismatch")
+	}
+}
+
+func TestMinimizeNilInterface(t *testing.T) {
+	build := func(input any) *scientist.Experiment[bool] {
+		e := scientist.New[bool]("nil")
+		e.Use(func(ctx context.Context) (bool, error) {
+			return input == nil, nil
+		})
+		e.Try(func(ctx context.Context) (any, error) {
+			return false, nil
+		})
+		return e
+	}
+
+	min, err := Minimize[any](nil, build)
+	if err != nil || min != nil {
+		t.Errorf("expected the nil input back, got %#v, %v", min, err)
+	}
+}
+
+func TestTestCase(t *testing.T) {
+	expected := `func TestOrderMismatch(t *testing.T) {
+	scientisttest.Check(t, order{Items: []item{item{Qty: 101}}}, orderExperiment)
+}
+`
+	if actual := TestCase("TestOrderMismatch", order{Items: []item{{Qty: 101}}}, orderExperiment); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	actual := TestCase("TestClosure", "x", func(s string) *scientist.Experiment[int] { return nil })
+	if expected := "\tscientisttest.Check(t, \"x\", build)\n"; !strings.Contains(actual, expected) {
+		t.Errorf("expected %q in:\n%s", expected, actual)
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	n := int64(7)
+	l := literal{pkg: "github.com/freshworks/go-scientist/scientisttest"}
+
+	for _, tt := range []struct {
+		value    any
+		expected string
+	}{
+		{[]any{1, int64(2), "3", 4.0, float32(5), nil, []int(nil)}, `[]any{1, int64(2), "3", 4.0, float32(5.0), nil, ([]int)(nil)}`},
+		{map[string]int{"b": 2, "a": 1}, `map[string]int{"a": 1, "b": 2}`},
+		{&n, `func() *int64 { v := int64(7); return &v }()`},
+		{&item{SKU: "a"}, `&item{SKU: "a"}`},
+		{time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC), `time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)`},
+		{time.Second, `time.Duration(1000000000)`},
+		{[2]bool{true}, `[2]bool{true, false}`},
+		{order{ID: "a long identifier to make the literal span lines", Items: []item{{"sku", 1}}}, `order{
+	ID: "a long identifier to make the literal span lines",
+	Items: []item{item{SKU: "sku", Qty: 1}},
+}`},
+	} {
+		actual := l.value(reflect.ValueOf(tt.value), true, 0)
+		if actual != tt.expected {
+			t.Errorf("%#v: expected %s, got %s", tt.value, tt.expected, actual)
+		}
+		if _, err := parser.ParseExpr(actual); err != nil {
+			t.Errorf("%s: %v", actual, err)
+		}
+	}
+}
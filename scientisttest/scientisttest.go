@@ -0,0 +1,209 @@
+// Package scientisttest helps testing experiments: a Recorder collects
+// published results, Force makes every experiment run its candidates before
+// Run returns, and the Require functions check results, printing a diff of
+// the control's and candidates' values on failure.
+package scientisttest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/freshworks/go-scientist"
+)
+
+// Recorder collects published results. It is safe to use from several
+// goroutines.
+type Recorder[T any] struct {
+	mu      sync.Mutex
+	results []*scientist.Result[T]
+	added   chan struct{}
+}
+
+func NewRecorder[T any]() *Recorder[T] {
+	return &Recorder[T]{added: make(chan struct{}, 1)}
+}
+
+// Publish records a result. Pass it to Experiment.Publish.
+func (r *Recorder[T]) Publish(result *scientist.Result[T]) error {
+	r.mu.Lock()
+	r.results = append(r.results, result)
+	r.mu.Unlock()
+
+	select {
+	case r.added <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Option returns an option publishing to the recorder.
+func (r *Recorder[T]) Option() scientist.Option {
+	return scientist.WithPublisher(r.Publish)
+}
+
+// Results returns the recorded results in the order they were published.
+func (r *Recorder[T]) Results() []*scientist.Result[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*scientist.Result[T](nil), r.results...)
+}
+
+// Last returns the last recorded result, or nil.
+func (r *Recorder[T]) Last() *scientist.Result[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.results) == 0 {
+		return nil
+	}
+	return r.results[len(r.results)-1]
+}
+
+func (r *Recorder[T]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.results)
+}
+
+func (r *Recorder[T]) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = nil
+}
+
+// Wait waits until n results were recorded, failing the test after timeout.
+// Use it for asynchronous experiments.
+func (r *Recorder[T]) Wait(t testing.TB, n int, timeout time.Duration) []*scientist.Result[T] {
+	t.Helper()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		if results := r.Results(); len(results) >= n {
+			return results
+		}
+
+		select {
+		case <-r.added:
+		case <-deadline.C:
+			t.Fatalf("timed out after %s waiting for %d results, got %d", timeout, n, r.Len())
+			return nil
+		}
+	}
+}
+
+// Force makes every experiment run synchronously and enabled, regardless of
+// RunIf, Enable, configuration files and flags, until the test ends. Tests
+// using Force can't run in parallel with tests depending on the usual
+// behavior.
+func Force(t testing.TB) {
+	t.Cleanup(scientist.Override(func(o *scientist.Overrides) {
+		o.Synchronous = true
+		o.Enabled = true
+	}))
+}
+
+// ForceSynchronous makes every experiment wait for its candidates before
+// Run returns, until the test ends.
+func ForceSynchronous(t testing.TB) {
+	t.Cleanup(scientist.Override(func(o *scientist.Overrides) {
+		o.Synchronous = true
+	}))
+}
+
+// RequireMatched fails the test unless every candidate of r matched the
+// control.
+func RequireMatched[T any](t testing.TB, r *scientist.Result[T]) {
+	t.Helper()
+	requireResult(t, r)
+
+	if len(r.Mismatched) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "experiment %s: expected a match, got %d mismatched candidates", r.Experiment.Name, len(r.Mismatched))
+	for _, o := range r.Mismatched {
+		writeMismatch(&b, r, o)
+	}
+	t.Fatal(b.String())
+}
+
+// RequireMismatched fails the test unless the candidate behavior of r
+// mismatched the control.
+func RequireMismatched[T any](t testing.TB, r *scientist.Result[T], behavior string) {
+	t.Helper()
+	requireResult(t, r)
+
+	for _, o := range r.Mismatched {
+		if o.Name == behavior {
+			return
+		}
+	}
+
+	for _, o := range r.Candidates {
+		if o.Name == behavior {
+			t.Fatalf("experiment %s: expected candidate %s to mismatch, but it matched\n  value: %#v", r.Experiment.Name, behavior, o.Value)
+		}
+	}
+	t.Fatalf("experiment %s: expected candidate %s to mismatch, but it didn't run (ran %s)", r.Experiment.Name, behavior, names(r.Candidates))
+}
+
+// RequireNoErrors fails the test if publishing or comparing r reported
+// errors, or any of its behaviors returned one.
+func RequireNoErrors[T any](t testing.TB, r *scientist.Result[T]) {
+	t.Helper()
+	requireResult(t, r)
+
+	var problems []string
+	for _, e := range r.Errors {
+		problems = append(problems, fmt.Sprintf("%s: %v", e.Operation, e.Err))
+	}
+	if r.Control.Err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %v", r.Control.Name, r.Control.Err))
+	}
+	for _, o := range r.Candidates {
+		if o.Err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", o.Name, o.Err))
+		}
+	}
+
+	if len(problems) > 0 {
+		t.Fatalf("experiment %s: expected no errors, got %d:\n  %s", r.Experiment.Name, len(problems), strings.Join(problems, "\n  "))
+	}
+}
+
+func requireResult[T any](t testing.TB, r *scientist.Result[T]) {
+	t.Helper()
+	if r == nil {
+		t.Fatal("expected a result, got nil: did the experiment run its candidates and publish?")
+	}
+}
+
+func writeMismatch[T any](b *strings.Builder, r *scientist.Result[T], o *scientist.Observation[T, any]) {
+	fmt.Fprintf(b, "\ncandidate %s:", o.Name)
+	if r.Control.Err != nil || o.Err != nil {
+		fmt.Fprintf(b, "\n  error:\n    - %s: %v\n    + %s: %v", r.Control.Name, r.Control.Err, o.Name, o.Err)
+	}
+	if diff := Diff(r.Control.Value, o.Value); diff != "" {
+		b.WriteString("\n")
+		b.WriteString(indent(diff, "  "))
+	}
+}
+
+func names[T any](observations []*scientist.Observation[T, any]) string {
+	if len(observations) == 0 {
+		return "none"
+	}
+	names := make([]string, len(observations))
+	for i, o := range observations {
+		names[i] = o.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n"+prefix)
+}
@@ -0,0 +1,180 @@
+package scientisttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/freshworks/go-scientist"
+)
+
+// fakeT records failures instead of failing the test.
+type fakeT struct {
+	testing.TB
+	failure  string
+	cleanups []func()
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+func (t *fakeT) Error(args ...any) {
+	t.failure += fmt.Sprint(args...)
+}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.failure += fmt.Sprintf(format, args...)
+}
+
+func (t *fakeT) Logf(format string, args ...any) {}
+
+func (t *fakeT) Fatal(args ...any) {
+	t.failure = fmt.Sprint(args...)
+	runtime.Goexit()
+}
+
+func (t *fakeT) Fatalf(format string, args ...any) {
+	t.failure = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// failure returns why fn failed the test, or "".
+func failure(fn func(t testing.TB)) string {
+	t := &fakeT{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(t)
+	}()
+	<-done
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+	return t.failure
+}
+
+type widget struct {
+	Name  string
+	Parts []string
+}
+
+func experiment(candidate widget, err error) *scientist.Experiment[widget] {
+	e := scientist.New[widget]("widget")
+	e.Use(func(ctx context.Context) (widget, error) {
+		return widget{Name: "gear", Parts: []string{"tooth", "axle"}}, nil
+	})
+	e.Behavior("new", func(ctx context.Context) (any, error) {
+		return candidate, err
+	})
+	e.RunIf(func() (bool, error) {
+		return false, nil
+	})
+	return e
+}
+
+func TestRecorderForce(t *testing.T) {
+	Force(t)
+
+	rec := NewRecorder[widget]()
+	e := experiment(widget{Name: "gear", Parts: []string{"tooth", "axle"}}, nil)
+	e.Publish(rec.Publish)
+
+	// Force overrides RunIf and waits for the candidates
+	if _, err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Len() != 1 {
+		t.Fatalf("expected a result, got %d", rec.Len())
+	}
+
+	RequireMatched(t, rec.Last())
+	RequireNoErrors(t, rec.Last())
+}
+
+func TestRecorderWait(t *testing.T) {
+	rec := NewRecorder[int]()
+	e := scientist.New[int]("async", rec.Option())
+	e.Use(func(ctx context.Context) (int, error) { return 1, nil })
+	e.Try(func(ctx context.Context) (any, error) { return 1, nil })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := e.Run(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if results := rec.Wait(t, 10, time.Second); len(results) != 10 {
+		t.Errorf("expected 10 results, got %d", len(results))
+	}
+
+	rec.Reset()
+	if msg := failure(func(ft testing.TB) { rec.Wait(ft, 1, 10*time.Millisecond) }); !strings.Contains(msg, "timed out") {
+		t.Errorf("expected Wait to time out, got %q", msg)
+	}
+}
+
+func TestRequireMatchedFailure(t *testing.T) {
+	Force(t)
+
+	rec := NewRecorder[widget]()
+	e := experiment(widget{Name: "gear", Parts: []string{"tooth", "spring"}}, nil)
+	e.Publish(rec.Publish)
+	if _, err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := failure(func(ft testing.TB) { RequireMatched(ft, rec.Last()) })
+	for _, expected := range []string{
+		"experiment widget: expected a match, got 1 mismatched candidates",
+		"candidate new:",
+		`.Parts[1]:`,
+		`- "axle"`,
+		`+ "spring"`,
+	} {
+		if !strings.Contains(msg, expected) {
+			t.Errorf("expected %q in failure:\n%s", expected, msg)
+		}
+	}
+
+	RequireMismatched(t, rec.Last(), "new")
+	if msg := failure(func(ft testing.TB) { RequireMismatched(ft, rec.Last(), "other") }); !strings.Contains(msg, "didn't run (ran new)") {
+		t.Errorf("unexpected failure: %q", msg)
+	}
+}
+
+func TestRequireNoErrorsFailure(t *testing.T) {
+	Force(t)
+
+	rec := NewRecorder[widget]()
+	e := experiment(widget{}, errors.New("boom"))
+	e.Publish(rec.Publish)
+	if _, err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := failure(func(ft testing.TB) { RequireNoErrors(ft, rec.Last()) })
+	if !strings.Contains(msg, "new: boom") {
+		t.Errorf("expected the candidate's error, got %q", msg)
+	}
+
+	if msg := failure(func(ft testing.TB) { RequireMatched(ft, rec.Last()) }); !strings.Contains(msg, "+ new: boom") {
+		t.Errorf("expected the errors in the diff, got %q", msg)
+	}
+	if msg := failure(func(ft testing.TB) { RequireMatched[int](ft, nil) }); !strings.Contains(msg, "expected a result") {
+		t.Errorf("unexpected failure for a nil result: %q", msg)
+	}
+}
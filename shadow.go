@@ -0,0 +1,191 @@
+package scientist
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Shadow runs every method of an interface as an experiment, with one
+// implementation as the control and another as the candidate. Go can't
+// implement an interface at runtime, so a thin wrapper type implements it
+// by forwarding each method to Call:
+//
+//	func (s shadowStore) Get(ctx context.Context, id int64) (*User, error) {
+//		out := s.shadow.Call(ctx, "Get", id)
+//		return scientist.As[*User](out[0]), scientist.As[error](out[1])
+//	}
+//
+// cmd/scientist-gen generates typed wrappers without reflection instead.
+//
+// The experiment of a method is named "<experiment>.<Method>". Its value is
+// the method's only result besides a trailing error, or a []any of them when
+// there are several.
+type Shadow[I any] struct {
+	name    string
+	methods map[string]*shadowMethod
+}
+
+type shadowMethod struct {
+	experiment *Experiment[any]
+	withCtx    bool
+	withErr    bool
+	outs       int
+}
+
+// NewShadow returns a Shadow of the methods of the interface I. Options
+// configure the experiment of every method.
+func NewShadow[I any](name string, control, candidate I, opts ...Option) (*Shadow[I], error) {
+	t := reflect.TypeOf((*I)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("shadow %s: %s is not an interface", name, t)
+	}
+
+	controlValue, candidateValue := reflect.ValueOf(&control).Elem(), reflect.ValueOf(&candidate).Elem()
+	if controlValue.IsNil() || candidateValue.IsNil() {
+		return nil, fmt.Errorf("shadow %s: nil implementation", name)
+	}
+
+	s := &Shadow[I]{name: name, methods: make(map[string]*shadowMethod, t.NumMethod())}
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		sm := &shadowMethod{
+			experiment: New[any](name+"."+m.Name, opts...),
+			withCtx:    m.Type.NumIn() > 0 && m.Type.In(0) == contextType,
+			withErr:    m.Type.NumOut() > 0 && m.Type.Out(m.Type.NumOut()-1) == errorType,
+			outs:       m.Type.NumOut(),
+		}
+
+		controlMethod, candidateMethod := controlValue.Method(i), candidateValue.Method(i)
+		sm.experiment.Use(func(ctx context.Context) (any, error) {
+			return sm.call(ctx, controlMethod)
+		})
+		sm.experiment.Try(func(ctx context.Context) (any, error) {
+			return sm.call(ctx, candidateMethod)
+		})
+		s.methods[m.Name] = sm
+	}
+	return s, nil
+}
+
+// Method configures the experiment of a method, like setting a comparator
+// or ignoring mismatches with WithComparator and WithIgnore. Configure the
+// methods before calling them.
+func (s *Shadow[I]) Method(name string, opts ...Option) error {
+	sm, ok := s.methods[name]
+	if !ok {
+		return fmt.Errorf("shadow %s has no method %s", s.name, name)
+	}
+	for _, opt := range opts {
+		opt(sm.experiment)
+	}
+	return nil
+}
+
+// Experiment returns the experiment of a method.
+func (s *Shadow[I]) Experiment(method string) *Experiment[any] {
+	if sm, ok := s.methods[method]; ok {
+		return sm.experiment
+	}
+	return nil
+}
+
+// Call runs the experiment of a method and returns the control's results.
+// args don't include a leading context.Context parameter, which is passed
+// ctx. Call panics for methods I doesn't have, and repanics when the control
+// of a method without an error result panics.
+func (s *Shadow[I]) Call(ctx context.Context, method string, args ...any) []any {
+	sm, ok := s.methods[method]
+	if !ok {
+		panic(fmt.Sprintf("scientist: shadow %s has no method %s", s.name, method))
+	}
+
+	v, err := sm.experiment.Run(ctx, Inputs(args...))
+	if err != nil && !sm.withErr {
+		panic(err)
+	}
+	return sm.results(v, err)
+}
+
+// call calls a method with the arguments of the run.
+func (sm *shadowMethod) call(ctx context.Context, method reflect.Value) (any, error) {
+	t := method.Type()
+	args := RunInputs(ctx)
+
+	in := make([]reflect.Value, 0, t.NumIn())
+	if sm.withCtx {
+		in = append(in, reflect.ValueOf(ctx))
+	}
+	for _, a := range args {
+		i := len(in)
+		if i >= t.NumIn() && !t.IsVariadic() {
+			return nil, fmt.Errorf("too many arguments: %d", len(args))
+		}
+		p := t.In(min(i, t.NumIn()-1))
+		if t.IsVariadic() && i >= t.NumIn()-1 {
+			p = p.Elem()
+		}
+		if a == nil {
+			in = append(in, reflect.Zero(p))
+		} else {
+			in = append(in, reflect.ValueOf(a))
+		}
+	}
+
+	out := method.Call(in)
+	var err error
+	if sm.withErr {
+		err, _ = out[len(out)-1].Interface().(error)
+		out = out[:len(out)-1]
+	}
+
+	switch len(out) {
+	case 0:
+		return nil, err
+	case 1:
+		return out[0].Interface(), err
+	default:
+		values := make([]any, len(out))
+		for i, o := range out {
+			values[i] = o.Interface()
+		}
+		return values, err
+	}
+}
+
+// results unpacks the value of a run into the method's results.
+func (sm *shadowMethod) results(v any, err error) []any {
+	values := sm.outs
+	if sm.withErr {
+		values--
+	}
+
+	out := make([]any, 0, sm.outs)
+	switch values {
+	case 0:
+	case 1:
+		out = append(out, v)
+	default:
+		vs, _ := v.([]any)
+		out = append(out, vs...)
+		for len(out) < values {
+			out = append(out, nil)
+		}
+	}
+
+	if sm.withErr {
+		out = append(out, err)
+	}
+	return out
+}
+
+// As converts a result of Call to T, returning the zero value for nil.
+func As[T any](v any) T {
+	t, _ := v.(T)
+	return t
+}
@@ -0,0 +1,152 @@
+package scientist
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type store interface {
+	Get(ctx context.Context, id int64) (*user, error)
+	Names(prefix string) []string
+	Delete(ctx context.Context, ids ...int64) error
+	Ping()
+}
+
+type memoryStore struct {
+	users map[int64]*user
+}
+
+func (s memoryStore) Get(ctx context.Context, id int64) (*user, error) {
+	u, ok := s.users[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return u, nil
+}
+
+func (s memoryStore) Names(prefix string) []string {
+	var names []string
+	for _, u := range s.users {
+		if strings.HasPrefix(u.Name, prefix) {
+			names = append(names, u.Name)
+		}
+	}
+	return names
+}
+
+func (s memoryStore) Delete(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return errors.New("nothing to delete")
+	}
+	return nil
+}
+
+func (s memoryStore) Ping() {}
+
+// shadowStore is what a wrapper of a Shadow looks like.
+type shadowStore struct {
+	shadow *Shadow[store]
+}
+
+func (s shadowStore) Get(ctx context.Context, id int64) (*user, error) {
+	out := s.shadow.Call(ctx, "Get", id)
+	return As[*user](out[0]), As[error](out[1])
+}
+
+func (s shadowStore) Names(prefix string) []string {
+	out := s.shadow.Call(context.Background(), "Names", prefix)
+	return As[[]string](out[0])
+}
+
+func (s shadowStore) Delete(ctx context.Context, ids ...int64) error {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	out := s.shadow.Call(ctx, "Delete", args...)
+	return As[error](out[0])
+}
+
+func (s shadowStore) Ping() {
+	s.shadow.Call(context.Background(), "Ping")
+}
+
+func TestShadow(t *testing.T) {
+	old := memoryStore{users: map[int64]*user{1: {1, "hubot"}, 2: {2, "octocat"}}}
+	new := memoryStore{users: map[int64]*user{1: {1, "hubot"}, 2: {2, "monalisa"}}}
+
+	var mu sync.Mutex
+	results := map[string]*Result[any]{}
+	shadow, err := NewShadow[store]("store", old, new,
+		WithSynchronous(true),
+		WithPublisher(func(r *Result[any]) error {
+			mu.Lock()
+			defer mu.Unlock()
+			results[r.Experiment.Name] = r
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = shadow.Method("Names", WithIgnore(func(control, candidate any) (bool, error) {
+		return true, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shadow.Method("Missing"); err == nil {
+		t.Error("expected an error configuring a missing method")
+	}
+
+	var s store = shadowStore{shadow}
+
+	u, err := s.Get(context.Background(), 2)
+	if err != nil || u.Name != "octocat" {
+		t.Errorf("expected the control's user, got %+v, %v", u, err)
+	}
+	r := results["store.Get"]
+	if r == nil || !r.IsMismatched() || !reflect.DeepEqual(r.Inputs, []any{int64(2)}) {
+		t.Errorf("expected a mismatch for Get(2), got %+v", r)
+	}
+
+	if _, err := s.Get(context.Background(), 3); err == nil || err.Error() != "not found" {
+		t.Errorf("expected the control's error, got %v", err)
+	}
+
+	if names := s.Names("octo"); !reflect.DeepEqual(names, []string{"octocat"}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+	if r := results["store.Names"]; r == nil || !r.IsIgnored() {
+		t.Errorf("expected the Names mismatch to be ignored, got %+v", r)
+	}
+
+	if err := s.Delete(context.Background(), 1, 2); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := s.Delete(context.Background()); err == nil {
+		t.Error("expected the control's error")
+	}
+	if r := results["store.Delete"]; r == nil || r.IsMismatched() {
+		t.Errorf("expected Delete to match, got %+v", r)
+	}
+
+	s.Ping()
+	if r := results["store.Ping"]; r == nil || r.IsMismatched() {
+		t.Errorf("expected Ping to match, got %+v", r)
+	}
+}
+
+func TestShadowErrors(t *testing.T) {
+	if _, err := NewShadow[memoryStore]("store", memoryStore{}, memoryStore{}); err == nil {
+		t.Error("expected an error shadowing a struct")
+	}
+	if _, err := NewShadow[store]("store", memoryStore{}, nil); err == nil {
+		t.Error("expected an error shadowing a nil implementation")
+	}
+}
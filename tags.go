@@ -0,0 +1,67 @@
+package scientist
+
+import (
+	"context"
+	"fmt"
+)
+
+type tagsKey struct{}
+
+// WithTag returns a copy of ctx carrying a tag for the results of
+// experiments run with it. Values can be of any type, like numbers, bools
+// or durations.
+func WithTag(ctx context.Context, key string, value any) context.Context {
+	tags := make(map[string]any)
+	for k, v := range Tags(ctx) {
+		tags[k] = v
+	}
+	tags[key] = value
+	return context.WithValue(ctx, tagsKey{}, tags)
+}
+
+// Tags returns the tags carried by ctx.
+func Tags(ctx context.Context) map[string]any {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]any)
+	return tags
+}
+
+// Tag adds a typed tag to the result of a run.
+func Tag(key string, value any) RunOption {
+	return func(o *runOptions) {
+		if o.tags == nil {
+			o.tags = make(map[string]any)
+		}
+		o.tags[key] = value
+	}
+}
+
+// mergeTags merges the experiment's context with the tags of a run. Later
+// tags override earlier ones.
+func mergeTags(context map[string]string, tags ...map[string]any) map[string]any {
+	merged := make(map[string]any, len(context))
+	for k, v := range context {
+		merged[k] = v
+	}
+	for _, m := range tags {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// tagStrings formats tags for Result.Context.
+func tagStrings(tags map[string]any) map[string]string {
+	strings := make(map[string]string, len(tags))
+	for k, v := range tags {
+		switch v := v.(type) {
+		case string:
+			strings[k] = v
+		case fmt.Stringer:
+			strings[k] = v.String()
+		default:
+			strings[k] = fmt.Sprint(v)
+		}
+	}
+	return strings
+}
@@ -0,0 +1,59 @@
+package scientist
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTags(t *testing.T) {
+	e := New[int]("tags")
+	basicExperiment(e)
+	e.Context["tenant"] = "acme"
+	e.Context["endpoint"] = "/default"
+
+	var result *Result[int]
+	e.Publish(func(r *Result[int]) error {
+		result = r
+		return nil
+	})
+
+	ctx := WithTag(context.Background(), "endpoint", "/widgets")
+	ctx = WithTag(ctx, "size", 42)
+	e.Run(ctx, Tag("cached", true), Tag("budget", 250*time.Millisecond), RunContext(map[string]string{"size": "large"}))
+
+	expected := map[string]any{
+		"tenant":   "acme",
+		"endpoint": "/widgets",
+		"size":     "large",
+		"cached":   true,
+		"budget":   250 * time.Millisecond,
+	}
+	for k, v := range expected {
+		if result.Tags[k] != v {
+			t.Errorf("expected tag %s to be %v (%T), got %v (%T)", k, v, v, result.Tags[k], result.Tags[k])
+		}
+	}
+
+	if result.Context["cached"] != "true" || result.Context["budget"] != "250ms" {
+		t.Errorf("expected formatted tags in the context, got %v", result.Context)
+	}
+	if e.Context["endpoint"] != "/default" {
+		t.Errorf("expected the experiment context to be unchanged, got %v", e.Context)
+	}
+}
+
+func TestWithTag(t *testing.T) {
+	parent := WithTag(context.Background(), "a", 1)
+	child := WithTag(parent, "b", 2)
+
+	if len(Tags(parent)) != 1 {
+		t.Errorf("expected WithTag to leave the parent's tags alone, got %v", Tags(parent))
+	}
+	if tags := Tags(child); tags["a"] != 1 || tags["b"] != 2 {
+		t.Errorf("expected inherited tags, got %v", tags)
+	}
+	if Tags(context.Background()) != nil {
+		t.Error("expected no tags")
+	}
+}
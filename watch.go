@@ -0,0 +1,101 @@
+package scientist
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// watcher polls a file and hands its contents to load whenever they change.
+type watcher struct {
+	path     string
+	load     func(path string, data []byte) error
+	onError  func(error)
+	done     chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+	loaded   bool
+	last     []byte
+	modified time.Time
+	err      error
+}
+
+// watchFile loads path once, failing if it can't, and then reloads it every
+// interval, passing reload errors to onError. An interval of zero disables
+// reloading.
+func watchFile(path string, interval time.Duration, load func(path string, data []byte) error, onError func(error)) (*watcher, error) {
+	w := &watcher{path: path, load: load, onError: onError, done: make(chan struct{})}
+	if err := w.reload(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go w.poll(interval)
+	}
+	return w, nil
+}
+
+func (w *watcher) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			if err := w.reload(); err != nil && w.onError != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
+
+// reload returns an error only the first time a given problem is seen, so a
+// broken file is reported once instead of on every tick.
+func (w *watcher) reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err == nil && w.loaded && info.ModTime().Equal(w.modified) && info.Size() == int64(len(w.last)) {
+		return nil
+	}
+
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(w.path)
+	}
+	if err == nil && (!w.loaded || !bytes.Equal(data, w.last)) {
+		err = w.load(w.path, data)
+	}
+	if err != nil {
+		// keep the last good contents until the file is fixed
+		err = fmt.Errorf("loading %s: %w", w.path, err)
+		if w.err != nil && w.err.Error() == err.Error() {
+			return nil
+		}
+		w.err = err
+		return err
+	}
+
+	w.loaded = true
+	w.last = data
+	w.modified = info.ModTime()
+	w.err = nil
+	return nil
+}
+
+func (w *watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *watcher) Close() {
+	w.once.Do(func() {
+		close(w.done)
+	})
+}
@@ -0,0 +1,108 @@
+package scientist
+
+import "context"
+
+// Wrap0 returns a function with the signature of control that runs an
+// experiment comparing control to candidate, and returns the control's
+// result. Options configure the experiment like in New.
+func Wrap0[R any](name string, control, candidate func(ctx context.Context) (R, error), opts ...Option) func(ctx context.Context) (R, error) {
+	run := wrap(name, func(ctx context.Context, args []any) (R, error) {
+		return control(ctx)
+	}, func(ctx context.Context, args []any) (R, error) {
+		return candidate(ctx)
+	}, opts)
+
+	return func(ctx context.Context) (R, error) {
+		return run(ctx)
+	}
+}
+
+// Wrap1 is Wrap0 for functions of one argument, which is recorded in
+// Result.Inputs. Use a struct for functions of more arguments than Wrap3
+// takes.
+func Wrap1[A, R any](name string, control, candidate func(ctx context.Context, a A) (R, error), opts ...Option) func(ctx context.Context, a A) (R, error) {
+	run := wrap(name, func(ctx context.Context, args []any) (R, error) {
+		return control(ctx, arg[A](args, 0))
+	}, func(ctx context.Context, args []any) (R, error) {
+		return candidate(ctx, arg[A](args, 0))
+	}, opts)
+
+	return func(ctx context.Context, a A) (R, error) {
+		return run(ctx, a)
+	}
+}
+
+// Wrap2 is Wrap0 for functions of two arguments.
+func Wrap2[A, B, R any](name string, control, candidate func(ctx context.Context, a A, b B) (R, error), opts ...Option) func(ctx context.Context, a A, b B) (R, error) {
+	run := wrap(name, func(ctx context.Context, args []any) (R, error) {
+		return control(ctx, arg[A](args, 0), arg[B](args, 1))
+	}, func(ctx context.Context, args []any) (R, error) {
+		return candidate(ctx, arg[A](args, 0), arg[B](args, 1))
+	}, opts)
+
+	return func(ctx context.Context, a A, b B) (R, error) {
+		return run(ctx, a, b)
+	}
+}
+
+// Wrap3 is Wrap0 for functions of three arguments.
+func Wrap3[A, B, C, R any](name string, control, candidate func(ctx context.Context, a A, b B, c C) (R, error), opts ...Option) func(ctx context.Context, a A, b B, c C) (R, error) {
+	run := wrap(name, func(ctx context.Context, args []any) (R, error) {
+		return control(ctx, arg[A](args, 0), arg[B](args, 1), arg[C](args, 2))
+	}, func(ctx context.Context, args []any) (R, error) {
+		return candidate(ctx, arg[A](args, 0), arg[B](args, 1), arg[C](args, 2))
+	}, opts)
+
+	return func(ctx context.Context, a A, b B, c C) (R, error) {
+		return run(ctx, a, b, c)
+	}
+}
+
+// WrapVariadic is Wrap0 for variadic functions. Each argument is recorded
+// separately in Result.Inputs.
+func WrapVariadic[A, R any](name string, control, candidate func(ctx context.Context, args ...A) (R, error), opts ...Option) func(ctx context.Context, args ...A) (R, error) {
+	typed := func(args []any) []A {
+		typed := make([]A, len(args))
+		for i := range args {
+			typed[i] = arg[A](args, i)
+		}
+		return typed
+	}
+
+	run := wrap(name, func(ctx context.Context, args []any) (R, error) {
+		return control(ctx, typed(args)...)
+	}, func(ctx context.Context, args []any) (R, error) {
+		return candidate(ctx, typed(args)...)
+	}, opts)
+
+	return func(ctx context.Context, args ...A) (R, error) {
+		untyped := make([]any, len(args))
+		for i, a := range args {
+			untyped[i] = a
+		}
+		return run(ctx, untyped...)
+	}
+}
+
+// wrap builds the experiment once; the arguments of each call reach the
+// behaviors as the inputs of the run.
+func wrap[R any](name string, control, candidate func(ctx context.Context, args []any) (R, error), opts []Option) func(ctx context.Context, args ...any) (R, error) {
+	e := New[R](name, opts...)
+	e.Use(func(ctx context.Context) (R, error) {
+		return control(ctx, RunInputs(ctx))
+	})
+	e.Try(func(ctx context.Context) (any, error) {
+		return candidate(ctx, RunInputs(ctx))
+	})
+
+	runner, _ := e.Build() // only fails without a control
+	return func(ctx context.Context, args ...any) (R, error) {
+		return runner.Run(ctx, Inputs(args...))
+	}
+}
+
+// arg returns args[i] as an A, or the zero value for a nil interface.
+func arg[A any](args []any, i int) A {
+	a, _ := args[i].(A)
+	return a
+}
@@ -0,0 +1,105 @@
+package scientist
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+func TestWrap1(t *testing.T) {
+	var results []*Result[*user]
+	find := Wrap1("find-user",
+		func(ctx context.Context, id int64) (*user, error) {
+			return &user{ID: id, Name: "hubot"}, nil
+		},
+		func(ctx context.Context, id int64) (*user, error) {
+			if id == 2 {
+				return nil, errors.New("not found")
+			}
+			return &user{ID: id, Name: "hubot"}, nil
+		},
+		WithSynchronous(true),
+		WithPublisher(func(r *Result[*user]) error {
+			results = append(results, r)
+			return nil
+		}),
+	)
+
+	for _, id := range []int64{1, 2} {
+		u, err := find(context.Background(), id)
+		if err != nil || u.ID != id {
+			t.Errorf("expected the control's user %d, got %+v, %v", id, u, err)
+		}
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if !reflect.DeepEqual(results[0].Inputs, []any{int64(1)}) || results[0].IsMismatched() {
+		t.Errorf("expected a match for input 1, got %v mismatched=%t", results[0].Inputs, results[0].IsMismatched())
+	}
+	if !reflect.DeepEqual(results[1].Inputs, []any{int64(2)}) || !results[1].IsMismatched() {
+		t.Errorf("expected a mismatch for input 2, got %v mismatched=%t", results[1].Inputs, results[1].IsMismatched())
+	}
+}
+
+func TestWrap3(t *testing.T) {
+	var inputs []any
+	join := Wrap3("join",
+		func(ctx context.Context, a string, b error, n int) (string, error) {
+			return strings.Repeat(a, n), b
+		},
+		func(ctx context.Context, a string, b error, n int) (string, error) {
+			return strings.Repeat(a, n), b
+		},
+		WithSynchronous(true),
+		WithPublisher(func(r *Result[string]) error {
+			inputs = r.Inputs
+			return nil
+		}),
+	)
+
+	// a nil interface argument reaches the behaviors as nil
+	s, err := join(context.Background(), "ab", nil, 2)
+	if s != "abab" || err != nil {
+		t.Errorf("unexpected result: %q, %v", s, err)
+	}
+	if !reflect.DeepEqual(inputs, []any{"ab", nil, 2}) {
+		t.Errorf("unexpected inputs: %v", inputs)
+	}
+}
+
+func TestWrapVariadic(t *testing.T) {
+	var result *Result[int]
+	sum := WrapVariadic("sum",
+		func(ctx context.Context, ns ...int) (int, error) {
+			total := 0
+			for _, n := range ns {
+				total += n
+			}
+			return total, nil
+		},
+		func(ctx context.Context, ns ...int) (int, error) {
+			return len(ns), nil
+		},
+		WithSynchronous(true),
+		WithPublisher(func(r *Result[int]) error {
+			result = r
+			return nil
+		}),
+	)
+
+	if n, _ := sum(context.Background(), 1, 2, 3); n != 6 {
+		t.Errorf("expected the control's sum, got %d", n)
+	}
+	if !reflect.DeepEqual(result.Inputs, []any{1, 2, 3}) || !result.IsMismatched() {
+		t.Errorf("expected a mismatch with inputs, got %v mismatched=%t", result.Inputs, result.IsMismatched())
+	}
+}